@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// configEnv names the config file used when --config isn't set
+const configEnv = "RECONCILIATION_CONFIG"
+
+// parseFlags parses the command line of a command, after setting its flags from the --config file, if any.
+// The config file is a JSON object of flag names to values, e.g. {"bank-files": "statements/", "date-buffer": 2},
+// shared by all the commands: each one takes the values of its own flags, and the command line overrides them
+func parseFlags(fs *flag.FlagSet, args []string) {
+	configFile := fs.String("config", os.Getenv(configEnv), "Path to a JSON file of default flag values, overridden by the command line (defaults to $"+configEnv+")")
+	fs.Parse(args)
+
+	if *configFile == "" {
+		return
+	}
+
+	values, err := loadConfig(*configFile)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Invalid config file: %v", err))
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	for name, value := range values {
+		if set[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			exitWithUsage(fmt.Sprintf("Invalid value of %s in config file %s: %v", name, *configFile, err))
+		}
+	}
+}
+
+// loadConfig reads the flag values of a config file, as they'd be written on the command line
+func loadConfig(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		name = strings.TrimLeft(name, "-")
+
+		// Strings are unquoted, numbers and booleans kept as written
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			values[name] = s
+			continue
+		}
+		values[name] = string(bytes.TrimSpace(value))
+	}
+
+	return values, nil
+}
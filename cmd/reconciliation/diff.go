@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/tirasundara/reconciliation-service/internal/diff"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+	"github.com/tirasundara/reconciliation-service/internal/store"
+)
+
+const diffUsage = `Usage:
+  reconciliation diff [--format text|json] [--output file] <previous report.json> <current report.json>
+  reconciliation diff --db runs.db [--format text|json] [--output file] <previous run ID> <current run ID>`
+
+// diffCommand lists what changed between two reconciliations of a period, given as JSON reports or as runs
+// recorded with --db
+func diffCommand(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	dbPath := fs.String("db", "", "Path to the SQLite database the runs were recorded in, to compare runs instead of JSON reports")
+	outputFormat := fs.String("format", "text", "Output format: text or json")
+	outputFile := fs.String("output", "", "Path to output file (if empty, writes to stdout)")
+	prettyPrint := fs.Bool("pretty", true, "Pretty print JSON output")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 2 {
+		exitWithUsage("Two reports or run IDs to compare are required\n" + diffUsage)
+	}
+	if *outputFormat != "text" && *outputFormat != "json" {
+		exitWithUsage(fmt.Sprintf("Invalid output format: unsupported diff format: %s", *outputFormat))
+	}
+
+	var previous, current report.Document
+	if *dbPath != "" {
+		ctx := context.Background()
+		s, err := store.Open(ctx, *dbPath)
+		if err != nil {
+			exitWithError(fmt.Sprintf("Failed to open run database: %v", err))
+		}
+		defer s.Close()
+
+		previous = readRunDocument(ctx, s, fs.Arg(0))
+		current = readRunDocument(ctx, s, fs.Arg(1))
+	} else {
+		previous = readReportDocument(fs.Arg(0))
+		current = readReportDocument(fs.Arg(1))
+	}
+
+	d := diff.Compare(previous, current)
+
+	output := diff.FormatText(d)
+	if *outputFormat == "json" {
+		var err error
+		if output, err = diff.FormatJSON(d, *prettyPrint); err != nil {
+			exitWithError(fmt.Sprintf("Failed to format diff: %v", err))
+		}
+		output = append(output, '\n')
+	}
+
+	if *outputFile == "" {
+		os.Stdout.Write(output)
+		return
+	}
+	if err := os.WriteFile(*outputFile, output, 0644); err != nil {
+		exitWithError(fmt.Sprintf("Failed to write output: %v", err))
+	}
+}
+
+// readReportDocument reads a report written with --format json
+func readReportDocument(path string) report.Document {
+	f, err := os.Open(path)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to open report: %v", err))
+	}
+	defer f.Close()
+
+	doc, err := report.ReadDocument(f)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to read report %s: %v", path, err))
+	}
+	return doc
+}
+
+// readRunDocument reads the result of a recorded run, as its JSON report
+func readRunDocument(ctx context.Context, s *store.Store, arg string) report.Document {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		exitWithUsage(fmt.Sprintf("Invalid run ID: %s", arg))
+	}
+
+	run, err := s.GetRun(ctx, id)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to read run: %v", err))
+	}
+	return report.NewDocument(run.Result)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/repository"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+// inputFlags are the flags naming and decoding the input files, shared by the commands reading them
+type inputFlags struct {
+	systemFile     string
+	systemDB       string
+	systemDBDriver string
+	systemQuery    string
+	bankFiles      string
+	systemEncoding string
+	bankEncoding   string
+	sheet          string
+	headerRow      int
+	buildIndex     bool // Set by the run command only
+}
+
+func addInputFlags(fs *flag.FlagSet) *inputFlags {
+	in := &inputFlags{}
+	fs.StringVar(&in.systemFile, "system-file", "", "Path to system transactions CSV or XLSX file, - reads CSV from stdin")
+	fs.StringVar(&in.systemDB, "system-db", "", "Path to a SQLite database, or postgres:// URL of a PostgreSQL one, holding the system transactions, read instead of --system-file")
+	fs.StringVar(&in.systemDBDriver, "system-db-driver", "", "Driver of --system-db, sqlite or pgx (defaults to pgx for postgres:// URLs, sqlite otherwise)")
+	fs.StringVar(&in.systemQuery, "system-query", "", "Query selecting the system transactions from --system-db, between the two date parameters: ? for SQLite, $1 and $2 for PostgreSQL (defaults to reading the transactions table)")
+	fs.StringVar(&in.bankFiles, "bank-files", "", "Comma-separated bank statement files, directories or glob patterns, each optionally prefixed with bankID=")
+	fs.StringVar(&in.systemEncoding, "system-encoding", "auto", "Encoding of the system transactions file: auto, utf-8, utf-16le, utf-16be, windows-1252 or iso-8859-1")
+	fs.StringVar(&in.bankEncoding, "bank-encoding", "auto", "Encoding of the bank statement files, like --system-encoding")
+	fs.StringVar(&in.sheet, "sheet", "", "Worksheet to read from XLSX input files (defaults to the first one)")
+	fs.IntVar(&in.headerRow, "header-row", 1, "Row number of the header in XLSX input files")
+	return in
+}
+
+// hasSystem reports whether a system transactions input is set
+func (in *inputFlags) hasSystem() bool {
+	return in.systemFile != "" || in.systemDB != ""
+}
+
+// query returns the query of --system-query, or the default one with the parameters in the style of driver
+func (in *inputFlags) query(driver string) string {
+	if in.systemQuery != "" {
+		return in.systemQuery
+	}
+	if driver == repository.PostgresDriver {
+		return fmt.Sprintf(defaultSystemQuery, "$1", "$2")
+	}
+	return fmt.Sprintf(defaultSystemQuery, "?", "?")
+}
+
+// inputs are the repositories reading the input files
+type inputs struct {
+	systemRepo  domain.SystemTransactionRepository // nil without a system transactions input
+	systemInput string
+	bankRepos   map[string]domain.BankTransactionRepository
+	bankInputs  []string
+	closers     []func() error
+}
+
+func (i *inputs) Close() {
+	for _, closeInput := range i.closers {
+		closeInput()
+	}
+}
+
+// open returns the repositories of the inputs set, reporting their rejected rows to reject. Indexing the files
+// stops when ctx is done
+func (in *inputFlags) open(ctx context.Context, reject func(fileutil.Reject)) (*inputs, error) {
+	sysEnc, err := fileutil.ParseEncoding(in.systemEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid system encoding: %w", err)
+	}
+
+	bankEnc, err := fileutil.ParseEncoding(in.bankEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bank encoding: %w", err)
+	}
+
+	opened := &inputs{bankRepos: make(map[string]domain.BankTransactionRepository)}
+
+	// Create system repository, "-" reads the transactions from stdin
+	switch {
+	case in.systemDB != "":
+		driver := in.systemDBDriver
+		if driver == "" {
+			driver = repository.SQLDriver(in.systemDB)
+		}
+
+		db, err := sql.Open(driver, in.systemDB)
+		if err != nil {
+			return nil, fmt.Errorf("opening system database: %w", err)
+		}
+		opened.closers = append(opened.closers, db.Close)
+
+		repo := repository.NewSQLSystemRepository(db, in.query(driver), sysTimeFormat)
+		repo.RejectHandler = reject
+		// SQLite stores times as text, PostgreSQL as timestamps
+		if driver == repository.SQLiteDriver {
+			repo.ArgLayout = sysTimeFormat
+		}
+		opened.systemRepo, opened.systemInput = repo, in.systemDB
+
+	case in.systemFile != "":
+		opened.systemRepo, err = newSystemRepository(in.systemFile, inputOptions{
+			encoding:   sysEnc,
+			sheet:      in.sheet,
+			headerRow:  in.headerRow,
+			buildIndex: in.buildIndex,
+			reject:     reject,
+		})
+		if err != nil {
+			opened.Close()
+			return nil, fmt.Errorf("building index: %w", err)
+		}
+		opened.systemInput = in.systemFile
+	}
+
+	// Create bank repositories, one per file: a bank can deliver several files
+	bankOptions := inputOptions{
+		encoding:   bankEnc,
+		sheet:      in.sheet,
+		headerRow:  in.headerRow,
+		buildIndex: in.buildIndex,
+		reject:     reject,
+	}
+
+	for _, spec := range strings.Split(in.bankFiles, ",") {
+		bankID, pattern := parseBankSpec(spec)
+		if pattern == "" {
+			continue
+		}
+
+		paths, err := fileutil.ExpandPaths(pattern)
+		if err != nil {
+			opened.Close()
+			return nil, fmt.Errorf("invalid bank statement files: %w", err)
+		}
+
+		// A file matched by several patterns is read, and hashed, once
+		for _, bankFile := range paths {
+			if _, found := opened.bankRepos[bankFile]; found {
+				continue
+			}
+
+			if err := ctx.Err(); err != nil {
+				opened.Close()
+				return nil, err
+			}
+
+			repo, err := newBankRepository(bankFile, bankID, bankOptions)
+			if err != nil {
+				opened.Close()
+				return nil, fmt.Errorf("building index: %w", err)
+			}
+			opened.bankRepos[bankFile] = repo
+			opened.bankInputs = append(opened.bankInputs, bankFile)
+		}
+	}
+
+	return opened, nil
+}
+
+// errNoBankFiles is returned by the commands requiring bank statements when none were found
+var errNoBankFiles = errors.New("no valid bank statement files provided")
+
+// inputOptions configure how the input files are read
+type inputOptions struct {
+	encoding   fileutil.Encoding // CSV files only
+	sheet      string            // XLSX files only
+	headerRow  int               // XLSX files only
+	buildIndex bool              // (Re)build the day index of CSV files
+	reject     func(fileutil.Reject)
+}
+
+// newSystemRepository returns the repository reading the system transactions of path, by its extension
+func newSystemRepository(path string, opts inputOptions) (domain.SystemTransactionRepository, error) {
+	if isXLSX(path) {
+		repo := repository.NewXLSXSystemRepository(path, sysTimeFormat)
+		repo.Sheet, repo.HeaderRow = opts.sheet, opts.headerRow
+		repo.RejectHandler = opts.reject
+		return repo, nil
+	}
+
+	repo := repository.NewCSVSystemRepository(path, sysTimeFormat)
+	repo.Encoding, repo.RejectHandler = opts.encoding, opts.reject
+	if path == stdinPath {
+		repo.Input = os.Stdin
+		return repo, nil
+	}
+
+	// Day indexes let the repositories seek straight to the reconciliation period
+	if opts.buildIndex {
+		if err := repo.BuildIndex(); err != nil {
+			return nil, err
+		}
+	}
+
+	return repo, nil
+}
+
+// newBankRepository returns the repository reading the statement of path, by its extension.
+// bankID overrides the bank identifier taken from the file name
+func newBankRepository(path, bankID string, opts inputOptions) (domain.BankTransactionRepository, error) {
+	if isXLSX(path) {
+		repo := repository.NewXLSXBankRepository(path, bankDateFormat)
+		repo.Sheet, repo.HeaderRow = opts.sheet, opts.headerRow
+		repo.RejectHandler = opts.reject
+		if bankID != "" {
+			repo.BankIdentifier = bankID
+		}
+		return repo, nil
+	}
+
+	repo := repository.NewCSVBankRepository(path, bankDateFormat)
+	repo.Encoding, repo.RejectHandler = opts.encoding, opts.reject
+	if bankID != "" {
+		repo.BankIdentifier = bankID
+	}
+
+	if opts.buildIndex {
+		if err := repo.BuildIndex(); err != nil {
+			return nil, err
+		}
+	}
+
+	return repo, nil
+}
+
+func isXLSX(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".xlsx")
+}
+
+// parseBankSpec splits a --bank-files entry into the optional bank identifier and the path, directory or
+// glob pattern of the files, e.g. "bank_abc=statements/abc/*.csv"
+func parseBankSpec(spec string) (bankID, pattern string) {
+	spec = strings.TrimSpace(spec)
+	if id, path, found := strings.Cut(spec, "="); found {
+		return strings.TrimSpace(id), strings.TrimSpace(path)
+	}
+	return "", spec
+}
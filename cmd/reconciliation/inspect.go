@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+const inspectUsage = `Usage:
+  reconciliation inspect [--system-file file] [--bank-files files] [--start-date YYYY-MM-DD --end-date YYYY-MM-DD]`
+
+// Bounds of the period read by the commands whose dates are optional, covering every transaction
+var (
+	minDate = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	maxDate = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+)
+
+// profile sums up the transactions of an input
+type profile struct {
+	role    string // system or the bank ID
+	path    string
+	credits domain.Total
+	debits  domain.Total // Summed as on a bank statement, negative
+	first   time.Time
+	last    time.Time
+}
+
+func (p *profile) add(date time.Time, amount decimal.Decimal) {
+	if p.credits.Count+p.debits.Count == 0 || date.Before(p.first) {
+		p.first = date
+	}
+	if date.After(p.last) {
+		p.last = date
+	}
+
+	if amount.IsNegative() {
+		p.debits.Add(amount)
+	} else {
+		p.credits.Add(amount)
+	}
+}
+
+func (p *profile) rows() int {
+	return p.credits.Count + p.debits.Count
+}
+
+// inspectCommand profiles input files: row count, date range and sums
+func inspectCommand(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	in := addInputFlags(fs)
+	startDateStr := fs.String("start-date", "", "Start date of the transactions profiled (YYYY-MM-DD, defaults to all)")
+	endDateStr := fs.String("end-date", "", "End date of the transactions profiled (YYYY-MM-DD, defaults to all)")
+	parseFlags(fs, args)
+
+	if !in.hasSystem() && in.bankFiles == "" {
+		exitWithUsage("A system transactions file or database, or bank statement files, are required\n" + inspectUsage)
+	}
+	startDate, endDate := parseOptionalPeriod(*startDateStr, *endDateStr)
+
+	rejects := &rejectRecorder{}
+	opened, err := in.open(context.Background(), rejects.Record)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to open inputs: %v", err))
+	}
+	defer opened.Close()
+
+	profiles, err := profileInputs(context.Background(), opened, startDate, endDate)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to read inputs: %v", err))
+	}
+	printProfiles(profiles, len(rejects.rejects))
+}
+
+// parseOptionalPeriod parses --start-date and --end-date when both are set, and otherwise returns a period
+// covering every transaction
+func parseOptionalPeriod(startDateStr, endDateStr string) (startDate, endDate time.Time) {
+	if startDateStr == "" && endDateStr == "" {
+		return minDate, maxDate
+	}
+	return parsePeriod(startDateStr, endDateStr)
+}
+
+// profileInputs reads every transaction of the inputs between startDate and endDate, the system transactions
+// first and then every bank statement file in order
+func profileInputs(ctx context.Context, opened *inputs, startDate, endDate time.Time) ([]*profile, error) {
+	var profiles []*profile
+
+	if opened.systemRepo != nil {
+		p := &profile{role: "system", path: opened.systemInput}
+		for txn, err := range opened.systemRepo.StreamTransactionsInRange(ctx, startDate, endDate) {
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", opened.systemInput, err)
+			}
+
+			amount := txn.Amount
+			if txn.Type == domain.Debit {
+				amount = amount.Neg()
+			}
+			p.add(txn.TransactionTime, amount)
+		}
+		profiles = append(profiles, p)
+	}
+
+	for _, path := range opened.bankInputs {
+		repo := opened.bankRepos[path]
+		p := &profile{role: repo.GetBankIdentifier(), path: path}
+		for txn, err := range repo.StreamTransactionsInRange(ctx, startDate, endDate) {
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", path, err)
+			}
+			p.add(txn.Date, txn.Amount)
+		}
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+func printProfiles(profiles []*profile, rejects int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INPUT\tFILE\tROWS\tFIRST\tLAST\tCREDITS\tCREDIT TOTAL\tDEBITS\tDEBIT TOTAL\tNET")
+	for _, p := range profiles {
+		first, last := "-", "-"
+		if p.rows() > 0 {
+			first, last = p.first.Format(dateFormat), p.last.Format(dateFormat)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%d\t%s\t%d\t%s\t%s\n",
+			p.role, p.path, p.rows(), first, last,
+			p.credits.Count, p.credits.Amount.StringFixed(2), p.debits.Count, p.debits.Amount.StringFixed(2),
+			p.credits.Amount.Add(p.debits.Amount).StringFixed(2))
+	}
+	w.Flush()
+
+	if rejects > 0 {
+		fmt.Printf("\n%d rejected rows, see the warnings\n", rejects)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+// defaultFormat returns the output format used without --format: text for someone reading the terminal,
+// json for a file or another program
+func defaultFormat(outputFile string) string {
+	if outputFile == "" && isTerminal(os.Stdout) {
+		return "text"
+	}
+	return "json"
+}
+
+// isTerminal reports whether f is a terminal rather than a file or a pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// newFormatter returns the formatter of the given output format
+func newFormatter(format string, prettyPrint bool) (report.OutputFormatter, error) {
+	switch format {
+	case "json":
+		return report.NewJSONFormatter(prettyPrint), nil
+	case "ndjson":
+		return report.NewNDJSONFormatter(), nil
+	case "csv":
+		return report.NewCSVFormatter(), nil
+	case "html":
+		return report.NewHTMLFormatter(""), nil
+	case "xlsx":
+		return report.NewXLSXFormatter(), nil
+	case "text":
+		// Colors unless they're turned off, see https://no-color.org
+		return report.NewTextFormatter(isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// writeOutput formats result and writes it to outputFile, or to stdout when it's empty. The files of a
+// multi-file format are written to outputFile when it's a directory, or bundled in a zip archive otherwise
+func writeOutput(formatter report.OutputFormatter, result domain.ReconciliationResult, outputFile string) error {
+	if requiresOutputFile(formatter) && outputFile == "" {
+		return errors.New("the output can't be written to stdout, it requires --output")
+	}
+
+	if multi, ok := formatter.(report.MultiFileFormatter); ok {
+		if isDir(outputFile) {
+			files, err := multi.FormatFiles(result)
+			if err != nil {
+				return fmt.Errorf("formatting output: %w", err)
+			}
+			return report.WriteFiles(outputFile, files)
+		}
+	}
+
+	output, err := formatter.Format(result)
+	if err != nil {
+		return fmt.Errorf("formatting output: %w", err)
+	}
+
+	// Output the result
+	if outputFile != "" {
+		// If no extension is provided, add the formatter's default extension
+		if !strings.Contains(outputFile, ".") {
+			outputFile = fmt.Sprintf("%s.%s", outputFile, formatter.FileExtension())
+		}
+
+		err := os.WriteFile(outputFile, output, 0644)
+		if err != nil {
+			return fmt.Errorf("writing output file: %w", err)
+		}
+
+	} else {
+
+		// Write output to stdout, ending with a single newline
+		fmt.Print(string(output))
+		if !bytes.HasSuffix(output, []byte("\n")) {
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+// requiresOutputFile reports whether the output of formatter is made of several files, or is binary
+func requiresOutputFile(formatter report.OutputFormatter) bool {
+	switch formatter.(type) {
+	case report.MultiFileFormatter, *report.XLSXFormatter:
+		return true
+	}
+	return false
+}
+
+// setRejects hands the rejected input rows to the formatters reporting them
+func setRejects(formatter report.OutputFormatter, rejects []fileutil.Reject) {
+	switch f := formatter.(type) {
+	case *report.XLSXFormatter:
+		f.Rejects = rejects
+	case *report.NDJSONFormatter:
+		f.Rejects = rejects
+	}
+}
+
+// isDir reports whether path names a directory: an existing one, or one ending with a path separator
+func isDir(path string) bool {
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(os.PathSeparator)) {
+		return true
+	}
+
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
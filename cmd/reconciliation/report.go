@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/tirasundara/reconciliation-service/internal/store"
+)
+
+const reportUsage = `Usage:
+  reconciliation report --db runs.db [--format json] [--pretty] [--output file] <run ID>`
+
+// reportCommand re-renders a run recorded with --db, in any output format
+func reportCommand(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	dbPath := fs.String("db", "", "Path to the SQLite database the runs were recorded in")
+	outputFormat := fs.String("format", "json", "Output format: text, json, ndjson, html, xlsx, or csv written to an --output directory or zip file")
+	outputFile := fs.String("output", "", "Path to output file (if empty, writes to stdout)")
+	prettyPrint := fs.Bool("pretty", true, "Pretty print JSON output")
+	parseFlags(fs, args)
+
+	if *dbPath == "" {
+		exitWithUsage("Run database path is required\n" + reportUsage)
+	}
+	if fs.NArg() != 1 {
+		exitWithUsage("A run ID is required\n" + reportUsage)
+	}
+
+	formatter, err := newFormatter(*outputFormat, *prettyPrint)
+	if err != nil {
+		exitWithUsage(fmt.Sprintf("Invalid output format: %v", err))
+	}
+	if requiresOutputFile(formatter) && *outputFile == "" {
+		exitWithUsage(fmt.Sprintf("The %s output can't be written to stdout, it requires --output", *outputFormat))
+	}
+
+	ctx := context.Background()
+	s, err := store.Open(ctx, *dbPath)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to open run database: %v", err))
+	}
+	defer s.Close()
+
+	run := getRun(ctx, s, fs.Args())
+
+	setRejects(formatter, run.Rejects)
+	if err := writeOutput(formatter, run.Result, *outputFile); err != nil {
+		exitWithError(fmt.Sprintf("Failed to write output: %v", err))
+	}
+}
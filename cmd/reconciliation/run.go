@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/journal"
+	"github.com/tirasundara/reconciliation-service/internal/matcher"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+	"github.com/tirasundara/reconciliation-service/internal/repository"
+	"github.com/tirasundara/reconciliation-service/internal/service"
+	"github.com/tirasundara/reconciliation-service/internal/store"
+)
+
+// runFlags are the flags of the run command, besides the input ones
+type runFlags struct {
+	startDate       string
+	endDate         string
+	outputFormat    string
+	outputFile      string
+	dateBufferDays  int
+	amountThreshold float64
+	prettyPrint     bool
+	timeout         time.Duration
+	streaming       bool
+	matchWorkers    int
+	dbPath          string
+	carryForward    bool
+	balancesFile    string
+	chartFile       string
+	journalFile     string
+}
+
+// runCommand reconciles the system transactions with the bank statements of a period
+func runCommand(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	in := addInputFlags(fs)
+	r := &runFlags{}
+
+	fs.StringVar(&r.startDate, "start-date", "", "Start date for reconciliation (YYYY-MM-DD)")
+	fs.StringVar(&r.endDate, "end-date", "", "End date for reconciliation (YYYY-MM-DD)")
+	fs.StringVar(&r.outputFormat, "format", "", "Output format: text, json, ndjson, html, xlsx, or csv written to an --output directory or zip file (defaults to text on a terminal, json otherwise)")
+	fs.StringVar(&r.outputFile, "output", "", "Path to output file (if empty, writes to stdout)")
+	fs.IntVar(&r.dateBufferDays, "date-buffer", 1, "Number of days to extend search range on both ends for matching")
+	fs.Float64Var(&r.amountThreshold, "amount-threshold", 0.10, "Maximum amount difference to consider transactions matched")
+	fs.BoolVar(&r.prettyPrint, "pretty", true, "Pretty print JSON output")
+	fs.BoolVar(&in.buildIndex, "build-index", false, "(Re)build the day index of every input file before reconciling, files must be sorted by date")
+	fs.BoolVar(&r.streaming, "stream", false, "Match the inputs day by day in bounded memory, files must be sorted by date")
+	fs.IntVar(&r.matchWorkers, "match-workers", 1, "Number of day shards matched concurrently, same result for any value (0 means one per CPU)")
+	fs.StringVar(&r.dbPath, "db", "", "Path to a SQLite database recording the run and its results, see the runs command (if empty, the run isn't recorded)")
+	fs.BoolVar(&r.carryForward, "carry-forward", false, "Clear the open items of past periods in --db, and keep this period's unmatched transactions open for the next ones")
+	fs.StringVar(&r.balancesFile, "balances", "", "Path to a CSV file of opening and closing balances per bank and for the book, checked against the transactions")
+	fs.StringVar(&r.chartFile, "chart-of-accounts", "", "Path to a JSON chart of accounts mapping every bank and discrepancy category to a ledger account, see --journal")
+	fs.StringVar(&r.journalFile, "journal", "", "Path to a CSV (.csv) or JSON file of journal entries proposed for the discrepancies and bank-only items, requires --chart-of-accounts")
+	fs.DurationVar(&r.timeout, "timeout", 0, "Maximum duration of the reconciliation run, e.g. 30s or 5m (0 means no limit)")
+
+	parseFlags(fs, args)
+
+	// Validate required flags
+	if !in.hasSystem() {
+		exitWithUsage("System transactions file path or database is required")
+	}
+	if in.bankFiles == "" {
+		exitWithUsage("At least one bank statement file path is required")
+	}
+	if r.carryForward && r.dbPath == "" {
+		exitWithUsage("Carrying open items forward requires --db")
+	}
+	if r.journalFile != "" && r.chartFile == "" {
+		exitWithUsage("Proposing journal entries requires --chart-of-accounts")
+	}
+
+	if r.outputFormat == "" {
+		r.outputFormat = defaultFormat(r.outputFile)
+	}
+
+	formatter, err := newFormatter(r.outputFormat, r.prettyPrint)
+	if err != nil {
+		exitWithUsage(fmt.Sprintf("Invalid output format: %v", err))
+	}
+	if requiresOutputFile(formatter) && r.outputFile == "" {
+		exitWithUsage(fmt.Sprintf("The %s output can't be written to stdout, it requires --output", r.outputFormat))
+	}
+
+	startDate, endDate := parsePeriod(r.startDate, r.endDate)
+
+	// Stop the run on Ctrl+C/SIGTERM, and when the optional timeout elapses, from the indexing of the inputs on.
+	// The run returns its failure rather than exiting, so its inputs and database are closed first
+	ctx, cancel := runContext(r.timeout)
+	err = r.run(ctx, fs, in, formatter, startDate, endDate)
+	cancel()
+	if err != nil {
+		exitWithError(err.Error())
+	}
+}
+
+// runContext returns the context of a run, cancelled on Ctrl+C/SIGTERM and once timeout elapses, if set
+func runContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// run reconciles the inputs of in over the period, and writes the result with formatter
+func (r *runFlags) run(ctx context.Context, fs *flag.FlagSet, in *inputFlags, formatter report.OutputFormatter, startDate, endDate time.Time) error {
+	var chart *journal.ChartOfAccounts
+	if r.journalFile != "" {
+		var err error
+		chart, err = journal.LoadChartOfAccounts(r.chartFile)
+		if err != nil {
+			return fmt.Errorf("invalid chart of accounts: %w", err)
+		}
+	}
+
+	startedAt := time.Now()
+	rejects := &rejectRecorder{}
+
+	opened, err := in.open(ctx, rejects.Record)
+	if err != nil {
+		return r.runError(fmt.Errorf("failed to open inputs: %w", err))
+	}
+	defer opened.Close()
+
+	if len(opened.bankRepos) == 0 {
+		return errNoBankFiles
+	}
+
+	// Create matcher with strategies
+	strategies := []matcher.MatchingStrategy{
+		matcher.NewExactMatchStrategy(),
+		matcher.NewFuzzyMatchStrategy(r.amountThreshold),
+		matcher.NewDateBufferMatchStrategy(r.dateBufferDays),
+	}
+
+	var matcherWithStrategies domain.TransactionMatcher = matcher.NewDefaultMatcher(strategies...)
+	switch {
+	case r.streaming:
+		matcherWithStrategies = matcher.NewStreamMatcher(r.dateBufferDays, strategies...)
+	case r.matchWorkers != 1:
+		matcherWithStrategies = matcher.NewParallelMatcher(r.dateBufferDays, r.matchWorkers, strategies...)
+	}
+
+	// Create reconciliation service
+	reconciliationService := service.NewReconciliationService(opened.systemRepo, opened.bankRepos, matcherWithStrategies, r.dateBufferDays)
+
+	if r.balancesFile != "" {
+		repo := repository.NewCSVBalanceRepository(r.balancesFile, dateFormat)
+		repo.RejectHandler = rejects.Record
+		reconciliationService.WithBalances(repo)
+	}
+
+	// The run database records the run, and keeps the open items carried forward
+	var runStore *store.Store
+	var runInputs []store.Input
+	if r.dbPath != "" {
+		runStore, err = store.Open(ctx, r.dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open run database: %w", err)
+		}
+		defer runStore.Close()
+
+		runInputs, err = hashInputs(ctx, opened.systemInput, opened.bankInputs)
+		if err != nil {
+			return r.runError(fmt.Errorf("failed to record run: %w", err))
+		}
+
+		if r.carryForward {
+			reconciliationService.WithOpenItems(runStore)
+		}
+	}
+
+	// Streamed to a streaming format, every record is written as soon as it's known, unless the whole result
+	// is needed to record the run, to check the balances or to propose journal entries
+	if streamingFormatter, ok := formatter.(report.StreamingFormatter); ok && r.streaming && runStore == nil && r.balancesFile == "" && r.journalFile == "" {
+		err := streamOutput(ctx, reconciliationService, streamingFormatter, startDate, endDate, r.outputFile, rejects)
+		var outErr *outputError
+		if errors.As(err, &outErr) {
+			return fmt.Errorf("failed to write output: %w", outErr.err)
+		}
+		if err != nil {
+			return r.runError(fmt.Errorf("reconciliation failed: %w", err))
+		}
+		return nil
+	}
+
+	// Run reconciliation
+	reconcile := reconciliationService.Reconcile
+	if r.streaming {
+		reconcile = reconciliationService.ReconcileStreaming
+	}
+
+	result, err := reconcile(ctx, startDate, endDate)
+	if err != nil {
+		return r.runError(fmt.Errorf("reconciliation failed: %w", err))
+	}
+
+	if runStore != nil {
+		id, err := recordRun(ctx, runStore, fs, runInputs, startedAt, startDate, endDate, result, rejects.rejects)
+		if err != nil {
+			return fmt.Errorf("failed to record run: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Recorded run %d in %s\n", id, r.dbPath)
+	}
+
+	if r.journalFile != "" {
+		if err := writeJournal(result, chart, r.journalFile); err != nil {
+			return fmt.Errorf("failed to write journal entries: %w", err)
+		}
+	}
+
+	setRejects(formatter, rejects.rejects)
+	if err := writeOutput(formatter, result, r.outputFile); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// parsePeriod parses the required --start-date and --end-date, the end date being inclusive
+func parsePeriod(startDateStr, endDateStr string) (startDate, endDate time.Time) {
+	if startDateStr == "" {
+		exitWithUsage("Start date is required")
+	}
+	if endDateStr == "" {
+		exitWithUsage("End date is required")
+	}
+
+	startDate, err := time.Parse(dateFormat, startDateStr)
+	if err != nil {
+		exitWithUsage(fmt.Sprintf("Invalid start date format: %v", err))
+	}
+
+	endDate, err = time.Parse(dateFormat, endDateStr)
+	if err != nil {
+		exitWithUsage(fmt.Sprintf("Invalid end date format: %v", err))
+	}
+
+	// Add a day to end date to make it inclusive
+	endDate = endDate.AddDate(0, 0, 1).Add(-time.Second)
+
+	// Ensure dates are in the correct order
+	if endDate.Before(startDate) {
+		exitWithUsage("End date must be after start date")
+	}
+
+	return startDate, endDate
+}
+
+// writeJournal writes the journal entries proposed for result to path, in the format of its extension
+func writeJournal(result domain.ReconciliationResult, chart *journal.ChartOfAccounts, path string) error {
+	entries, err := journal.Build(result, chart)
+	if err != nil {
+		return err
+	}
+
+	data, err := journal.Format(entries, path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing journal file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Proposed %d journal entries in %s\n", len(entries), path)
+	return nil
+}
+
+// runError returns err, or the reason the run stopped when it was cancelled or timed out
+func (r *runFlags) runError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("reconciliation timed out after %s", r.timeout)
+	case errors.Is(err, context.Canceled):
+		return errors.New("reconciliation cancelled")
+	}
+	return err
+}
+
+// streamOutput reconciles the input streams, and writes the record of every outcome to outputFile, or to
+// stdout when it's empty, as soon as it's known. The rejected rows and the summary follow them. Failures to
+// write the output are returned as an *outputError
+func streamOutput(
+	ctx context.Context,
+	reconciliationService *service.ReconciliationService,
+	formatter report.StreamingFormatter,
+	startDate, endDate time.Time,
+	outputFile string,
+	rejects *rejectRecorder,
+) error {
+	if outputFile == "" {
+		return streamRecords(ctx, reconciliationService, formatter, startDate, endDate, os.Stdout, rejects)
+	}
+
+	if !strings.Contains(outputFile, ".") {
+		outputFile = fmt.Sprintf("%s.%s", outputFile, formatter.FileExtension())
+	}
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return &outputError{fmt.Errorf("creating output file: %w", err)}
+	}
+
+	if err := streamRecords(ctx, reconciliationService, formatter, startDate, endDate, f, rejects); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return &outputError{err}
+	}
+	return nil
+}
+
+// streamRecords writes the records of streamOutput to out
+func streamRecords(
+	ctx context.Context,
+	reconciliationService *service.ReconciliationService,
+	formatter report.StreamingFormatter,
+	startDate, endDate time.Time,
+	out io.Writer,
+	rejects *rejectRecorder,
+) error {
+	buf := bufio.NewWriter(out)
+	w := formatter.NewRecordWriter(buf)
+
+	summary, aging, err := reconciliationService.ReconcileToSinkWithTotals(ctx, startDate, endDate, outputSink{w})
+	if err != nil {
+		return err
+	}
+
+	for _, reject := range rejects.rejects {
+		if err := w.Reject(reject); err != nil {
+			return &outputError{err}
+		}
+	}
+	if err := w.Aging(aging); err != nil {
+		return &outputError{err}
+	}
+	if err := w.Summary(summary); err != nil {
+		return &outputError{err}
+	}
+
+	if err := buf.Flush(); err != nil {
+		return &outputError{err}
+	}
+	return nil
+}
+
+// outputError is a failure to write the output, rather than to reconcile
+type outputError struct {
+	err error
+}
+
+func (e *outputError) Error() string {
+	return fmt.Sprintf("writing output: %v", e.err)
+}
+
+func (e *outputError) Unwrap() error {
+	return e.err
+}
+
+// outputSink returns the failures of its sink to write an outcome as an *outputError
+type outputSink struct {
+	sink domain.MatchSink
+}
+
+func (s outputSink) Matched(match domain.Match) error {
+	return asOutputError(s.sink.Matched(match))
+}
+
+func (s outputSink) UnmatchedSystem(txn domain.SystemTransaction) error {
+	return asOutputError(s.sink.UnmatchedSystem(txn))
+}
+
+func (s outputSink) UnmatchedBank(txn domain.BankTransaction) error {
+	return asOutputError(s.sink.UnmatchedBank(txn))
+}
+
+func asOutputError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &outputError{err}
+}
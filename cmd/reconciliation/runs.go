@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/store"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+const runsUsage = `Usage:
+  reconciliation runs list --db runs.db
+  reconciliation runs show --db runs.db <run ID>
+  reconciliation runs export --db runs.db [--format json] [--pretty] [--output file] <run ID>, like report`
+
+// rejectRecorder prints the warning of every rejected row, and keeps them for the run database
+type rejectRecorder struct {
+	mu      sync.Mutex
+	rejects []fileutil.Reject
+}
+
+func (r *rejectRecorder) Record(reject fileutil.Reject) {
+	fmt.Fprintf(os.Stderr, "Warning: %s line %d: %v\n", reject.File, reject.Line, reject.Err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rejects = append(r.rejects, reject)
+}
+
+// hashInputs returns the inputs of a run, with the SHA-256 of their files. They're hashed before reconciling, so
+// the hashes are those of the bytes reconciled even when a file is replaced once the run is over. Hashing stops
+// when ctx is done
+func hashInputs(ctx context.Context, systemInput string, bankInputs []string) ([]store.Input, error) {
+	input, err := store.HashInput("system", systemInput)
+	if err != nil {
+		return nil, err
+	}
+	inputs := []store.Input{input}
+
+	for _, path := range bankInputs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		input, err := store.HashInput("bank", path)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input)
+	}
+
+	return inputs, nil
+}
+
+// recordRun saves a reconciliation run in the run database, with its hashed inputs and the value of every flag
+// of fs, and returns its ID
+func recordRun(
+	ctx context.Context,
+	s *store.Store,
+	fs *flag.FlagSet,
+	inputs []store.Input,
+	startedAt, startDate, endDate time.Time,
+	result domain.ReconciliationResult,
+	rejects []fileutil.Reject,
+) (int64, error) {
+	run := &store.Run{
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Params:     make(map[string]string),
+		Inputs:     inputs,
+		Result:     result,
+		Rejects:    rejects,
+	}
+
+	fs.VisitAll(func(f *flag.Flag) {
+		run.Params[f.Name] = f.Value.String()
+	})
+
+	return s.SaveRun(ctx, run)
+}
+
+// runsCommand lists and shows the runs recorded with --db, and re-exports them like the report command
+func runsCommand(args []string) {
+	if len(args) == 0 {
+		exitWithUsage("A runs command is required\n" + runsUsage)
+	}
+
+	if args[0] == "export" {
+		reportCommand(args[1:])
+		return
+	}
+
+	fs := flag.NewFlagSet("runs "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", "", "Path to the SQLite database the runs were recorded in")
+	parseFlags(fs, args[1:])
+
+	if *dbPath == "" {
+		exitWithUsage("Run database path is required")
+	}
+
+	ctx := context.Background()
+	s, err := store.Open(ctx, *dbPath)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to open run database: %v", err))
+	}
+	defer s.Close()
+
+	switch args[0] {
+	case "list":
+		runs, err := s.ListRuns(ctx)
+		if err != nil {
+			exitWithError(fmt.Sprintf("Failed to list runs: %v", err))
+		}
+		printRuns(runs)
+
+	case "show":
+		run := getRun(ctx, s, fs.Args())
+		printRun(run)
+
+	default:
+		exitWithUsage(fmt.Sprintf("Unknown runs command: %s\n%s", args[0], runsUsage))
+	}
+}
+
+// getRun reads the run whose ID is the single argument
+func getRun(ctx context.Context, s *store.Store, args []string) *store.Run {
+	if len(args) != 1 {
+		exitWithUsage("A run ID is required\n" + runsUsage)
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		exitWithUsage(fmt.Sprintf("Invalid run ID: %s", args[0]))
+	}
+
+	run, err := s.GetRun(ctx, id)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to read run: %v", err))
+	}
+
+	return run
+}
+
+func printRuns(runs []store.RunSummary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTARTED\tPERIOD\tMATCHED\tUNMATCHED SYSTEM\tUNMATCHED BANK\tREJECTS\tDISCREPANCIES")
+	for _, run := range runs {
+		fmt.Fprintf(w, "%d\t%s\t%s to %s\t%d\t%d\t%d\t%d\t%s\n",
+			run.ID, run.StartedAt.Format(time.DateTime), run.StartDate.Format(dateFormat), run.EndDate.Format(dateFormat),
+			run.Matched, run.UnmatchedSystem, run.UnmatchedBank, run.Rejects, run.TotalDiscrepancies)
+	}
+	w.Flush()
+}
+
+func printRun(run *store.Run) {
+	unmatchedBank := 0
+	for _, txns := range run.Result.UnMatchedBankTxns {
+		unmatchedBank += len(txns)
+	}
+
+	fmt.Printf("Run %d\n", run.ID)
+	fmt.Printf("  Started:  %s (%s)\n", run.StartedAt.Format(time.DateTime), run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond))
+	fmt.Printf("  Period:   %s to %s\n", run.StartDate.Format(dateFormat), run.EndDate.Format(dateFormat))
+	fmt.Printf("  Results:  %d matched, %d unmatched system, %d unmatched bank, %d rejected, %s discrepancies\n",
+		len(run.Result.MatchedTxns), len(run.Result.UnMatchedSystemTxns), unmatchedBank, len(run.Rejects), run.Result.TotalDiscrepancies)
+
+	fmt.Println("\nInputs:")
+	for _, input := range run.Inputs {
+		hash := input.SHA256
+		if hash == "" {
+			hash = "(not hashed)"
+		}
+		fmt.Printf("  %-6s  %s  %s\n", input.Role, hash, input.Path)
+	}
+
+	fmt.Println("\nParameters:")
+	for _, name := range slices.Sorted(maps.Keys(run.Params)) {
+		fmt.Printf("  --%s=%s\n", name, run.Params[name])
+	}
+
+	if len(run.Rejects) > 0 {
+		fmt.Println("\nRejected rows:")
+		for _, reject := range run.Rejects {
+			fmt.Printf("  %s line %d: %v\n", reject.File, reject.Line, reject.Err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/report"
+	"github.com/tirasundara/reconciliation-service/internal/store"
+)
+
+const serveUsage = `Usage:
+  reconciliation serve --db runs.db [--addr localhost:8080]`
+
+// runDocument is a run of the GET /runs list
+type runDocument struct {
+	ID                 int64  `json:"id"`
+	StartedAt          string `json:"started_at"`
+	FinishedAt         string `json:"finished_at"`
+	StartDate          string `json:"start_date"`
+	EndDate            string `json:"end_date"`
+	Matched            int    `json:"matched"`
+	UnmatchedSystem    int    `json:"unmatched_system"`
+	UnmatchedBank      int    `json:"unmatched_bank"`
+	Rejects            int    `json:"rejects"`
+	TotalDiscrepancies string `json:"total_discrepancies"`
+}
+
+// serveCommand serves the runs recorded with --db over HTTP:
+//
+//	GET /runs               the runs, newest first, as JSON
+//	GET /runs/{id}          the report of a run, in the ?format= output format, json by default
+//	GET /healthz            200 while the server is up
+func serveCommand(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	dbPath := fs.String("db", "", "Path to the SQLite database the runs were recorded in")
+	addr := fs.String("addr", "localhost:8080", "Address to listen on. The runs are served without authentication, so listening on other interfaces, e.g. :8080, exposes them to the network")
+	parseFlags(fs, args)
+
+	if *dbPath == "" {
+		exitWithUsage("Run database path is required\n" + serveUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s, err := store.Open(ctx, *dbPath)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to open run database: %v", err))
+	}
+	defer s.Close()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           newRunsHandler(s),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving the runs of %s on %s\n", *dbPath, *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		exitWithError(fmt.Sprintf("Failed to serve: %v", err))
+	}
+}
+
+// newRunsHandler returns the handler of the serve command's routes
+func newRunsHandler(s *store.Store) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("GET /runs", func(w http.ResponseWriter, r *http.Request) {
+		runs, err := s.ListRuns(r.Context())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("listing runs: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		docs := make([]runDocument, 0, len(runs))
+		for _, run := range runs {
+			docs = append(docs, runDocument{
+				ID:                 run.ID,
+				StartedAt:          run.StartedAt.Format(time.RFC3339),
+				FinishedAt:         run.FinishedAt.Format(time.RFC3339),
+				StartDate:          run.StartDate.Format(dateFormat),
+				EndDate:            run.EndDate.Format(dateFormat),
+				Matched:            run.Matched,
+				UnmatchedSystem:    run.UnmatchedSystem,
+				UnmatchedBank:      run.UnmatchedBank,
+				Rejects:            run.Rejects,
+				TotalDiscrepancies: run.TotalDiscrepancies.String(),
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(docs)
+	})
+
+	mux.HandleFunc("GET /runs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid run ID: %s", r.PathValue("id")), http.StatusBadRequest)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+
+		formatter, err := newFormatter(format, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if format == "text" {
+			// No colors, whatever the server's terminal
+			formatter = report.NewTextFormatter(false)
+		}
+
+		run, err := s.GetRun(r.Context(), id)
+		if errors.Is(err, store.ErrRunNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		setRejects(formatter, run.Rejects)
+		output, err := formatter.Format(run.Result)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("formatting output: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		contentType := mime.TypeByExtension("." + formatter.FileExtension())
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		if requiresOutputFile(formatter) {
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=run-%d.%s", id, formatter.FileExtension()))
+		}
+		w.Write(output)
+	})
+
+	return mux
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/journal"
+	"github.com/tirasundara/reconciliation-service/internal/repository"
+)
+
+const validateUsage = `Usage:
+  reconciliation validate [--system-file file] [--bank-files files] [--balances file] [--chart-of-accounts file]
+                          [--start-date YYYY-MM-DD --end-date YYYY-MM-DD]`
+
+// journalKeys are the chart of accounts keys every bank needs an account for
+var journalKeys = []string{
+	journal.AccountBank,
+	journal.CategoryRounding,
+	journal.CategoryFXDifference,
+	journal.CategoryBankFee,
+	journal.CategoryBankCredit,
+}
+
+// validateCommand checks the inputs of a run without matching them: every row of the input files must parse,
+// and the balances and the chart of accounts must cover the banks of the statements. It exits with exitInvalid
+// when it finds a problem
+func validateCommand(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	in := addInputFlags(fs)
+	startDateStr := fs.String("start-date", "", "Start date of the transactions checked (YYYY-MM-DD, defaults to all)")
+	endDateStr := fs.String("end-date", "", "End date of the transactions checked (YYYY-MM-DD, defaults to all)")
+	balancesFile := fs.String("balances", "", "Path to a CSV file of opening and closing balances per bank and for the book")
+	chartFile := fs.String("chart-of-accounts", "", "Path to a JSON chart of accounts, checked to map every bank and discrepancy category")
+	parseFlags(fs, args)
+
+	if !in.hasSystem() && in.bankFiles == "" && *balancesFile == "" && *chartFile == "" {
+		exitWithUsage("Nothing to validate\n" + validateUsage)
+	}
+	startDate, endDate := parseOptionalPeriod(*startDateStr, *endDateStr)
+
+	ctx := context.Background()
+	rejects := &rejectRecorder{}
+
+	opened, err := in.open(ctx, rejects.Record)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to open inputs: %v", err))
+	}
+	defer opened.Close()
+
+	if in.bankFiles != "" && len(opened.bankRepos) == 0 {
+		exitWithError("No valid bank statement files provided")
+	}
+
+	var problems []string
+
+	profiles, err := profileInputs(ctx, opened, startDate, endDate)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	for _, p := range profiles {
+		fmt.Printf("%s: %d transactions\n", p.path, p.rows())
+		if p.rows() == 0 {
+			problems = append(problems, fmt.Sprintf("no transactions in %s", p.path))
+		}
+	}
+
+	var bankIDs []string
+	for _, repo := range opened.bankRepos {
+		if !slices.Contains(bankIDs, repo.GetBankIdentifier()) {
+			bankIDs = append(bankIDs, repo.GetBankIdentifier())
+		}
+	}
+	slices.Sort(bankIDs)
+
+	if *balancesFile != "" {
+		// The balances of a period are only known given the period
+		balanceBankIDs := bankIDs
+		if *startDateStr == "" {
+			balanceBankIDs = nil
+		}
+		problems = append(problems, validateBalances(ctx, *balancesFile, startDate, endDate, balanceBankIDs, rejects)...)
+	}
+	if *chartFile != "" {
+		problems = append(problems, validateChart(*chartFile, bankIDs)...)
+	}
+
+	if len(rejects.rejects) > 0 {
+		problems = append(problems, fmt.Sprintf("%d rejected rows", len(rejects.rejects)))
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("OK")
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Found %d problems:\n", len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "  %s\n", problem)
+	}
+	os.Exit(exitInvalid)
+}
+
+// validateBalances reads the balances of the period from the balances file, and checks it has those of every
+// bank of bankIDs
+func validateBalances(ctx context.Context, path string, startDate, endDate time.Time, bankIDs []string, rejects *rejectRecorder) []string {
+	repo := repository.NewCSVBalanceRepository(path, dateFormat)
+	repo.RejectHandler = rejects.Record
+
+	balances, err := repo.GetBalances(ctx, startDate, endDate)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	for _, bankID := range bankIDs {
+		if _, found := balances.Bank[bankID]; !found {
+			problems = append(problems, fmt.Sprintf("no balances of bank %s in %s", bankID, path))
+		}
+	}
+	return problems
+}
+
+// validateChart reads the chart of accounts, and checks it maps every bank and category to an account
+func validateChart(path string, bankIDs []string) []string {
+	chart, err := journal.LoadChartOfAccounts(path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	for _, bankID := range bankIDs {
+		for _, key := range journalKeys {
+			if _, err := chart.Account(bankID, key); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	return problems
+}
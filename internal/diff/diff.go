@@ -0,0 +1,235 @@
+// Package diff compares two reconciliations of the same period, e.g. before and after fixing their inputs
+package diff
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+)
+
+// Sides of a transaction
+const (
+	SideSystem = "system"
+	SideBank   = "bank"
+)
+
+// Diff lists what changed from a reconciliation to another one. Transactions are identified by their key: the
+// ID of a system transaction, the bank ID and ID of a bank transaction, as in "bank_a/BNK-1"
+type Diff struct {
+	NewlyMatched       []Pair              `json:"newly_matched"`       // Pairs of transactions unmatched before
+	NewlyUnmatched     []Item              `json:"newly_unmatched"`     // Transactions matched before, or new ones
+	Repaired           []Repair            `json:"repaired"`            // Pairs of which a transaction had another counterpart
+	DiscrepancyChanged []DiscrepancyChange `json:"discrepancy_changed"` // Pairs whose amount difference changed
+	Removed            []Item              `json:"removed"`             // Transactions no longer reconciled
+}
+
+// Pair is a matched pair of a system and a bank transaction
+type Pair struct {
+	System           string `json:"system"`
+	Bank             string `json:"bank"`
+	AmountDifference string `json:"amount_difference"`
+	Strategy         string `json:"strategy"`
+}
+
+// Repair is a pair of which the system or the bank transaction was matched with another counterpart before
+type Repair struct {
+	Pair
+	PreviousSystem string `json:"previous_system,omitempty"` // Counterpart the bank transaction had
+	PreviousBank   string `json:"previous_bank,omitempty"`   // Counterpart the system transaction had
+}
+
+// DiscrepancyChange is a pair matched in both reconciliations with another amount difference
+type DiscrepancyChange struct {
+	System   string `json:"system"`
+	Bank     string `json:"bank"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// Item is an unmatched or removed transaction, with the counterpart it was matched with before, if any
+type Item struct {
+	Side          string `json:"side"`
+	Key           string `json:"key"`
+	Amount        string `json:"amount"`
+	Date          string `json:"date"`
+	PreviousMatch string `json:"previous_match,omitempty"`
+}
+
+// Empty reports whether nothing changed
+func (d Diff) Empty() bool {
+	return len(d.NewlyMatched) == 0 && len(d.NewlyUnmatched) == 0 && len(d.Repaired) == 0 &&
+		len(d.DiscrepancyChanged) == 0 && len(d.Removed) == 0
+}
+
+// txnState is a transaction of a reconciliation, and the counterpart it's matched with, if any
+type txnState struct {
+	item  Item
+	match *report.MatchDocument
+}
+
+// reconciliation indexes the transactions of a report by their key
+type reconciliation struct {
+	system map[string]txnState
+	bank   map[string]txnState
+}
+
+// Compare lists what changed from the previous report to the current one
+func Compare(previous, current report.Document) Diff {
+	before, after := index(previous), index(current)
+	d := Diff{
+		NewlyMatched:       []Pair{},
+		NewlyUnmatched:     []Item{},
+		Repaired:           []Repair{},
+		DiscrepancyChanged: []DiscrepancyChange{},
+		Removed:            []Item{},
+	}
+
+	for _, match := range current.Matched {
+		pair := newPair(match)
+		prevSystem, prevBank := before.system[pair.System].match, before.bank[pair.Bank].match
+
+		switch {
+		case prevSystem == nil && prevBank == nil:
+			d.NewlyMatched = append(d.NewlyMatched, pair)
+
+		case prevSystem != nil && bankKey(prevSystem.Bank) == pair.Bank:
+			if !equalAmounts(prevSystem.AmountDifference, match.AmountDifference) {
+				d.DiscrepancyChanged = append(d.DiscrepancyChanged, DiscrepancyChange{
+					System:   pair.System,
+					Bank:     pair.Bank,
+					Previous: prevSystem.AmountDifference,
+					Current:  match.AmountDifference,
+				})
+			}
+
+		default:
+			repair := Repair{Pair: pair}
+			if prevSystem != nil {
+				repair.PreviousBank = bankKey(prevSystem.Bank)
+			}
+			if prevBank != nil {
+				repair.PreviousSystem = prevBank.System.ID
+			}
+			d.Repaired = append(d.Repaired, repair)
+		}
+	}
+
+	d.NewlyUnmatched = append(d.NewlyUnmatched, newlyUnmatched(before.system, after.system)...)
+	d.NewlyUnmatched = append(d.NewlyUnmatched, newlyUnmatched(before.bank, after.bank)...)
+	d.Removed = append(d.Removed, removed(before.system, after.system)...)
+	d.Removed = append(d.Removed, removed(before.bank, after.bank)...)
+
+	slices.SortFunc(d.NewlyMatched, func(a, b Pair) int { return cmp.Compare(a.System, b.System) })
+	slices.SortFunc(d.Repaired, func(a, b Repair) int { return cmp.Compare(a.System, b.System) })
+	slices.SortFunc(d.DiscrepancyChanged, func(a, b DiscrepancyChange) int { return cmp.Compare(a.System, b.System) })
+
+	return d
+}
+
+// newlyUnmatched returns the transactions unmatched after, that were matched or missing before
+func newlyUnmatched(before, after map[string]txnState) []Item {
+	var items []Item
+	for _, key := range slices.Sorted(maps.Keys(after)) {
+		state := after[key]
+		if state.match != nil {
+			continue
+		}
+
+		prev, ok := before[key]
+		if ok && prev.match == nil {
+			continue
+		}
+
+		item := state.item
+		if ok {
+			item.PreviousMatch = counterpart(item.Side, prev.match)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// removed returns the transactions reconciled before, but not after
+func removed(before, after map[string]txnState) []Item {
+	var items []Item
+	for _, key := range slices.Sorted(maps.Keys(before)) {
+		if _, ok := after[key]; ok {
+			continue
+		}
+
+		prev := before[key]
+		item := prev.item
+		if prev.match != nil {
+			item.PreviousMatch = counterpart(item.Side, prev.match)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func index(doc report.Document) reconciliation {
+	r := reconciliation{
+		system: make(map[string]txnState),
+		bank:   make(map[string]txnState),
+	}
+
+	for i := range doc.Matched {
+		match := &doc.Matched[i]
+		r.system[match.System.ID] = txnState{item: systemItem(match.System), match: match}
+		r.bank[bankKey(match.Bank)] = txnState{item: bankItem(match.Bank), match: match}
+	}
+
+	for _, txn := range doc.UnmatchedSystem {
+		r.system[txn.ID] = txnState{item: systemItem(txn)}
+	}
+
+	for _, txns := range doc.UnmatchedBank {
+		for _, txn := range txns {
+			r.bank[bankKey(txn)] = txnState{item: bankItem(txn)}
+		}
+	}
+
+	return r
+}
+
+func newPair(match report.MatchDocument) Pair {
+	return Pair{
+		System:           match.System.ID,
+		Bank:             bankKey(match.Bank),
+		AmountDifference: match.AmountDifference,
+		Strategy:         match.Strategy,
+	}
+}
+
+func systemItem(txn report.SystemTxnDocument) Item {
+	return Item{Side: SideSystem, Key: txn.ID, Amount: txn.Amount, Date: txn.TransactionTime}
+}
+
+func bankItem(txn report.BankTxnDocument) Item {
+	return Item{Side: SideBank, Key: bankKey(txn), Amount: txn.Amount, Date: txn.Date}
+}
+
+// counterpart returns the key of the other transaction of match, for a transaction of side
+func counterpart(side string, match *report.MatchDocument) string {
+	if side == SideSystem {
+		return bankKey(match.Bank)
+	}
+	return match.System.ID
+}
+
+// equalAmounts reports whether two decimal strings are the same amount, e.g. "0.5" and "0.50"
+func equalAmounts(a, b string) bool {
+	x, errX := decimal.NewFromString(a)
+	y, errY := decimal.NewFromString(b)
+	if errX != nil || errY != nil {
+		return a == b
+	}
+	return x.Equal(y)
+}
+
+func bankKey(txn report.BankTxnDocument) string {
+	return txn.BankID + "/" + txn.ID
+}
@@ -0,0 +1,97 @@
+package diff_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/diff"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+)
+
+var day = time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+
+func sysTxn(id, amount string) domain.SystemTransaction {
+	return domain.SystemTransaction{TrxID: id, Amount: decimal.RequireFromString(amount), Type: domain.Credit, TransactionTime: day}
+}
+
+func bankTxn(id, amount string) domain.BankTransaction {
+	return domain.BankTransaction{UniqID: id, Amount: decimal.RequireFromString(amount), Date: day, BankID: "bank_a"}
+}
+
+func match(sys domain.SystemTransaction, bank domain.BankTransaction, amountDiff string) domain.Match {
+	return domain.Match{SystemTxn: sys, BankTxn: bank, AmmountDiff: decimal.RequireFromString(amountDiff), Strategy: "fuzzy"}
+}
+
+func TestCompare(t *testing.T) {
+	previous := report.NewDocument(domain.ReconciliationResult{
+		MatchedTxns: []domain.Match{
+			match(sysTxn("SYS-1", "100.05"), bankTxn("BNK-1", "100"), "0.05"),
+			match(sysTxn("SYS-2", "50"), bankTxn("BNK-2", "50"), "0"),
+		},
+		UnMatchedSystemTxns: []domain.SystemTransaction{sysTxn("SYS-3", "20"), sysTxn("SYS-4", "30")},
+		UnMatchedBankTxns:   map[string][]domain.BankTransaction{"bank_a": {bankTxn("BNK-3", "50")}},
+	})
+
+	current := report.NewDocument(domain.ReconciliationResult{
+		MatchedTxns: []domain.Match{
+			match(sysTxn("SYS-1", "100"), bankTxn("BNK-1", "100"), "0"),
+			match(sysTxn("SYS-2", "50"), bankTxn("BNK-3", "50"), "0"),
+			match(sysTxn("SYS-3", "20"), bankTxn("BNK-5", "20"), "0"),
+		},
+		UnMatchedSystemTxns: []domain.SystemTransaction{sysTxn("SYS-6", "60")},
+		UnMatchedBankTxns:   map[string][]domain.BankTransaction{"bank_a": {bankTxn("BNK-2", "50")}},
+	})
+
+	d := diff.Compare(previous, current)
+
+	tests := []struct {
+		name     string
+		actual   any
+		expected string
+	}{
+		{"Newly matched", d.NewlyMatched, "[{SYS-3 bank_a/BNK-5 0 fuzzy}]"},
+		{"Newly unmatched", d.NewlyUnmatched, "[{system SYS-6 60 2025-01-03T00:00:00Z } {bank bank_a/BNK-2 50 2025-01-03 SYS-2}]"},
+		{"Re-paired", d.Repaired, "[{{SYS-2 bank_a/BNK-3 0 fuzzy}  bank_a/BNK-2}]"},
+		{"Discrepancy changed", d.DiscrepancyChanged, "[{SYS-1 bank_a/BNK-1 0.05 0}]"},
+		{"Removed", d.Removed, "[{system SYS-4 30 2025-01-03T00:00:00Z }]"},
+	}
+
+	for _, tt := range tests {
+		if actual := fmt.Sprint(tt.actual); actual != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, actual)
+		}
+	}
+
+	text := string(diff.FormatText(d))
+	if !strings.Contains(text, "Re-paired (1)\n  SYS-2 = bank_a/BNK-3, SYS-2 was matched with bank_a/BNK-2\n") {
+		t.Errorf("Expected the re-paired section in the text output, got\n%s", text)
+	}
+}
+
+func TestCompare_NoChanges(t *testing.T) {
+	doc := report.NewDocument(domain.ReconciliationResult{
+		MatchedTxns: []domain.Match{match(sysTxn("SYS-1", "100"), bankTxn("BNK-1", "100"), "0")},
+	})
+
+	d := diff.Compare(doc, doc)
+	if !d.Empty() {
+		t.Errorf("Expected no changes, got %+v", d)
+	}
+
+	if text := string(diff.FormatText(d)); text != "No changes\n" {
+		t.Errorf("Expected 'No changes', got %q", text)
+	}
+
+	data, err := diff.FormatJSON(d, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"newly_matched":[],"newly_unmatched":[],"repaired":[],"discrepancy_changed":[],"removed":[]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
@@ -0,0 +1,73 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// FormatJSON returns d as JSON
+func FormatJSON(d Diff, prettyPrint bool) ([]byte, error) {
+	if prettyPrint {
+		return json.MarshalIndent(d, "", "  ")
+	}
+	return json.Marshal(d)
+}
+
+// FormatText returns d as text for a terminal, a section per kind of change
+func FormatText(d Diff) []byte {
+	var buf bytes.Buffer
+	if d.Empty() {
+		buf.WriteString("No changes\n")
+		return buf.Bytes()
+	}
+
+	section := func(title string, count int) {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "%s (%d)\n", title, count)
+	}
+
+	section("Newly matched", len(d.NewlyMatched))
+	for _, pair := range d.NewlyMatched {
+		fmt.Fprintf(&buf, "  %s = %s  difference %s (%s)\n", pair.System, pair.Bank, pair.AmountDifference, pair.Strategy)
+	}
+
+	section("Newly unmatched", len(d.NewlyUnmatched))
+	for _, item := range d.NewlyUnmatched {
+		fmt.Fprintf(&buf, "  %-6s %s  %s  %s%s\n", item.Side, item.Key, item.Amount, item.Date, previousMatch(item, "was matched with", "new"))
+	}
+
+	section("Re-paired", len(d.Repaired))
+	for _, repair := range d.Repaired {
+		fmt.Fprintf(&buf, "  %s = %s", repair.System, repair.Bank)
+		if repair.PreviousBank != "" {
+			fmt.Fprintf(&buf, ", %s was matched with %s", repair.System, repair.PreviousBank)
+		}
+		if repair.PreviousSystem != "" {
+			fmt.Fprintf(&buf, ", %s was matched with %s", repair.Bank, repair.PreviousSystem)
+		}
+		buf.WriteString("\n")
+	}
+
+	section("Discrepancy changed", len(d.DiscrepancyChanged))
+	for _, change := range d.DiscrepancyChanged {
+		fmt.Fprintf(&buf, "  %s = %s  %s -> %s\n", change.System, change.Bank, change.Previous, change.Current)
+	}
+
+	section("Removed", len(d.Removed))
+	for _, item := range d.Removed {
+		fmt.Fprintf(&buf, "  %-6s %s  %s  %s%s\n", item.Side, item.Key, item.Amount, item.Date, previousMatch(item, "matched with", "unmatched"))
+	}
+
+	return buf.Bytes()
+}
+
+// previousMatch describes the counterpart item had before, or the lack of one
+func previousMatch(item Item, matched, otherwise string) string {
+	if item.PreviousMatch != "" {
+		return fmt.Sprintf("  (%s %s)", matched, item.PreviousMatch)
+	}
+	return fmt.Sprintf("  (%s)", otherwise)
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// Aging reports the unmatched transactions by age in days at the end of the period
+type Aging struct {
+	System AgingBuckets            // Unmatched system transactions
+	Bank   map[string]AgingBuckets // Unmatched bank transactions, by bank
+}
+
+// AgingBuckets are the totals of every aging bucket, youngest first
+type AgingBuckets []AgingBucket
+
+// AgingBucket counts and sums the transactions whose age falls in a range of days, e.g. "3-7"
+type AgingBucket struct {
+	Bucket string
+	Count  int
+	Amount decimal.Decimal // Sum of the amounts as on a bank statement, debits being negative
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// StatementBalance holds the opening and closing balances of an account over a reconciliation period
+type StatementBalance struct {
+	Account string // Account number, informational. The accounts of a bank with several, comma-separated
+	Opening decimal.Decimal
+	Closing decimal.Decimal
+}
+
+// Balances are the balances of the accounts reconciled over a period
+type Balances struct {
+	Book *StatementBalance           // Ledger of the system transactions, nil when unknown
+	Bank map[string]StatementBalance // By bank
+}
+
+// BalanceReconciliation checks the balances of the period against its transactions, and reconciles the bank
+// balance with the book balance
+type BalanceReconciliation struct {
+	Book *BalanceCheck           // nil when the book balances are unknown
+	Bank map[string]BalanceCheck // Banks with known balances
+
+	// Statement is nil unless the balances of the book and of every bank are known
+	Statement *BankToBookStatement
+}
+
+// BalanceCheck verifies that the opening balance plus the transactions of the period make the closing balance.
+// A difference means transactions are missing from the input, or the balances belong to another period
+type BalanceCheck struct {
+	Account      string
+	Opening      decimal.Decimal
+	Transactions Total // Transactions dated in the period
+	Closing      decimal.Decimal
+	Difference   decimal.Decimal // Closing balance minus opening balance and transactions
+	Balanced     bool
+}
+
+// BankToBookStatement is the classic bank reconciliation statement at the end of the period: the bank and the
+// book balances adjusted by the items only one side knows about should be equal. Amounts are signed as on a
+// bank statement, payments being negative
+type BankToBookStatement struct {
+	BankBalance         decimal.Decimal // Closing balance of every bank
+	DepositsInTransit   Total           // System credits missing from the bank statements
+	OutstandingPayments Total           // System debits missing from the bank statements
+	AdjustedBankBalance decimal.Decimal
+
+	BookBalance         decimal.Decimal // Closing balance of the book
+	BankOnlyItems       Total           // Bank transactions missing from the book, fees, interest, etc.
+	AdjustedBookBalance decimal.Decimal
+
+	// Difference is the adjusted bank balance minus the adjusted book balance, the discrepancies of the
+	// matches when the inputs are complete
+	Difference decimal.Decimal
+}
@@ -7,4 +7,5 @@ type Match struct {
 	SystemTxn   SystemTransaction
 	BankTxn     BankTransaction
 	AmmountDiff decimal.Decimal
+	Strategy    string // Name of the strategy that matched the pair, e.g. "exact"
 }
@@ -1,8 +1,26 @@
 package domain
 
+import (
+	"context"
+	"iter"
+)
+
 // TransactionMatcher defines the interface for matching system transactions with bank transactions
 type TransactionMatcher interface {
-	FindMatches(systemTxns []SystemTransaction, bankTxns []BankTransaction) ([]Match, error)
+	FindMatches(ctx context.Context, systemTxns []SystemTransaction, bankTxns []BankTransaction) ([]Match, error)
+}
+
+// StreamingTransactionMatcher defines the interface for matching date-sorted transaction streams in bounded memory.
+// bankStreams holds one stream per source, in the order the sources would be given to a TransactionMatcher
+type StreamingTransactionMatcher interface {
+	MatchStreams(ctx context.Context, systemTxns iter.Seq2[SystemTransaction, error], bankStreams []iter.Seq2[BankTransaction, error], sink MatchSink) error
+}
+
+// MatchSink receives the outcome of a streaming match as soon as it is known
+type MatchSink interface {
+	Matched(match Match) error
+	UnmatchedSystem(txn SystemTransaction) error
+	UnmatchedBank(txn BankTransaction) error
 }
 
 // MatchingStrategy defines a specific strategy for matching transactions
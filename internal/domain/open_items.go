@@ -0,0 +1,26 @@
+package domain
+
+// OpenItems are unmatched transactions carried forward from one reconciliation period to the next
+type OpenItems struct {
+	SystemTxns []SystemTransaction
+	BankTxns   []BankTransaction
+}
+
+// CarriedForward reports what became of the open items of past periods
+type CarriedForward struct {
+	Cleared        []Match                 // Open items matched with a transaction of this period
+	OpenSystemTxns []AgedSystemTransaction // Items still open at the end of this period
+	OpenBankTxns   []AgedBankTransaction
+}
+
+// AgedSystemTransaction is an open system transaction, with its age in days at the end of the period
+type AgedSystemTransaction struct {
+	SystemTransaction
+	AgeDays int
+}
+
+// AgedBankTransaction is an open bank transaction, with its age in days at the end of the period
+type AgedBankTransaction struct {
+	BankTransaction
+	AgeDays int
+}
@@ -4,9 +4,17 @@ import "github.com/shopspring/decimal"
 
 // ReconciliationResult containts the result of a reconciliation process
 type ReconciliationResult struct {
-	TotalTxnsProcessed  int
+	TotalTxnsProcessed  int // Transactions of the period, a match being two of them
 	MatchedTxns         []Match
 	UnMatchedSystemTxns []SystemTransaction
 	UnMatchedBankTxns   map[string][]BankTransaction // Grouped by bank
 	TotalDiscrepancies  decimal.Decimal
+	Summary             Summary
+	Aging               Aging
+
+	// CarriedForward reports the open items of past periods, nil when they aren't carried forward
+	CarriedForward *CarriedForward
+
+	// Balances checks the statement balances of the period, nil when they aren't known
+	Balances *BalanceReconciliation
 }
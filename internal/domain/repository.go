@@ -1,24 +1,53 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"iter"
+	"time"
+)
 
 // SystemTransactionRepository defines the interface for accessing system transactions
 type SystemTransactionRepository interface {
 	// GetTransactionsInRange gets system transactions for specified date between startDate and endDate
-	GetTransactionsInRange(startDate, endDate time.Time) ([]SystemTransaction, error)
+	GetTransactionsInRange(ctx context.Context, startDate, endDate time.Time) ([]SystemTransaction, error)
 
 	// GetTransactionsInRangeConcurrently is a concurrent version of GetTransactionsInRange()
-	GetTransactionsInRangeConcurrently(startDate, endDate time.Time) ([]SystemTransaction, error)
+	GetTransactionsInRangeConcurrently(ctx context.Context, startDate, endDate time.Time) ([]SystemTransaction, error)
+
+	// StreamTransactionsInRange yields system transactions between startDate and endDate in source order,
+	// without loading them all in memory. A failure is yielded as the last element with a non-nil error
+	StreamTransactionsInRange(ctx context.Context, startDate, endDate time.Time) iter.Seq2[SystemTransaction, error]
 }
 
 // BankTransactionRepository defines the interface for accessing bank transactions
 type BankTransactionRepository interface {
 	// GetTransactionsInRange gets bank transactions for specified date between startDate and endDate
-	GetTransactionsInRange(startDate, endDate time.Time) ([]BankTransaction, error)
+	GetTransactionsInRange(ctx context.Context, startDate, endDate time.Time) ([]BankTransaction, error)
 
 	// GetTransactionsInRangeConcurrently is a concurrent version of GetTransactionsInRange()
-	GetTransactionsInRangeConcurrently(startDate, endDate time.Time) ([]BankTransaction, error)
+	GetTransactionsInRangeConcurrently(ctx context.Context, startDate, endDate time.Time) ([]BankTransaction, error)
+
+	// StreamTransactionsInRange yields bank transactions between startDate and endDate in source order,
+	// without loading them all in memory. A failure is yielded as the last element with a non-nil error
+	StreamTransactionsInRange(ctx context.Context, startDate, endDate time.Time) iter.Seq2[BankTransaction, error]
 
 	// GetBankIdentifier returns bank identifier
 	GetBankIdentifier() string
 }
+
+// OpenItemRepository keeps the transactions left unmatched by past reconciliation periods, so later periods
+// can clear them instead of reporting their counterpart as a new exception
+type OpenItemRepository interface {
+	// GetOpenItems gets the items still open, dated before the given date
+	GetOpenItems(ctx context.Context, before time.Time) (OpenItems, error)
+
+	// UpdateOpenItems marks the items on either side of the closed matches as cleared in the period ending
+	// on periodEnd, and records the newly opened items
+	UpdateOpenItems(ctx context.Context, periodEnd time.Time, closed []Match, opened OpenItems) error
+}
+
+// BalanceRepository provides the opening and closing balances of the accounts reconciled
+type BalanceRepository interface {
+	// GetBalances gets the balances of the period between startDate and endDate
+	GetBalances(ctx context.Context, startDate, endDate time.Time) (Balances, error)
+}
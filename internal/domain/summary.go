@@ -0,0 +1,36 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// Summary totals the transactions of a reconciliation period. Amounts are summed as on a bank statement,
+// system debits being negative
+type Summary struct {
+	SystemTxns Total            // System transactions of the period, matched or not
+	BankTxns   map[string]Total // Bank transactions of the period, matched or not, by bank
+
+	Matched           Total            // Matched pairs, summed by their bank amount
+	MatchedByStrategy map[string]Total // Matched pairs by the strategy that matched them
+	ClearedOpenItems  Total            // Transactions of the period clearing an open item of a past period
+
+	UnmatchedSystem Total
+	UnmatchedBank   map[string]Total // By bank
+
+	// MatchRate is the share of the transactions of the period that were matched, between 0 and 1
+	MatchRate float64
+
+	// NetUnexplainedDifference is the system total minus the bank total: the unmatched transactions and the
+	// discrepancies of the matched pairs, net of each other
+	NetUnexplainedDifference decimal.Decimal
+}
+
+// Total counts and sums transactions
+type Total struct {
+	Count  int
+	Amount decimal.Decimal
+}
+
+// Add counts a transaction of the given amount
+func (t *Total) Add(amount decimal.Decimal) {
+	t.Count++
+	t.Amount = t.Amount.Add(amount)
+}
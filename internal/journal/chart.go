@@ -0,0 +1,88 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/shopspring/decimal"
+)
+
+// Categories of the journal entries, and the chart of accounts keys of their accounts
+const (
+	CategoryRounding     = "rounding"      // Matched pairs differing by up to the rounding threshold
+	CategoryFXDifference = "fx_difference" // Matched pairs differing by more than the rounding threshold
+	CategoryBankFee      = "bank_fee"      // Bank-only debits, e.g. fees and charges
+	CategoryBankCredit   = "bank_credit"   // Bank-only credits, e.g. interest
+
+	// AccountBank is the chart of accounts key of the ledger account of the bank itself
+	AccountBank = "bank"
+)
+
+// defaultRoundingThreshold is the largest difference of a matched pair booked as rounding when the chart of
+// accounts doesn't set one
+var defaultRoundingThreshold = decimal.RequireFromString("0.01")
+
+// ChartOfAccounts maps the bank and every category to a ledger account, for all banks or for one of them
+type ChartOfAccounts struct {
+	// RoundingThreshold is the largest difference of a matched pair booked as rounding, larger ones are
+	// FX differences
+	RoundingThreshold decimal.Decimal
+
+	// Accounts are the accounts of every bank, by category or AccountBank
+	Accounts map[string]string
+
+	// Banks override Accounts for a bank, by bank ID
+	Banks map[string]map[string]string
+}
+
+// chartFile is the JSON file of a chart of accounts
+type chartFile struct {
+	RoundingThreshold *decimal.Decimal             `json:"rounding_threshold"`
+	Accounts          map[string]string            `json:"accounts"`
+	Banks             map[string]map[string]string `json:"banks"`
+}
+
+// LoadChartOfAccounts reads a chart of accounts from a JSON file like:
+//
+//	{
+//	  "rounding_threshold": "0.01",
+//	  "accounts": {"bank": "1000", "rounding": "7900", "fx_difference": "7100", "bank_fee": "6100", "bank_credit": "4900"},
+//	  "banks": {"bank_a": {"bank": "1010"}}
+//	}
+func LoadChartOfAccounts(path string) (*ChartOfAccounts, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading chart of accounts: %w", err)
+	}
+
+	var file chartFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing chart of accounts %s: %w", path, err)
+	}
+
+	chart := &ChartOfAccounts{
+		RoundingThreshold: defaultRoundingThreshold,
+		Accounts:          file.Accounts,
+		Banks:             file.Banks,
+	}
+	if file.RoundingThreshold != nil {
+		if file.RoundingThreshold.IsNegative() {
+			return nil, fmt.Errorf("negative rounding threshold in chart of accounts %s", path)
+		}
+		chart.RoundingThreshold = *file.RoundingThreshold
+	}
+
+	return chart, nil
+}
+
+// Account returns the ledger account of key, a category or AccountBank, for bankID
+func (c *ChartOfAccounts) Account(bankID, key string) (string, error) {
+	if account := c.Banks[bankID][key]; account != "" {
+		return account, nil
+	}
+	if account := c.Accounts[key]; account != "" {
+		return account, nil
+	}
+	return "", fmt.Errorf("no %s account for bank %s in chart of accounts", key, bankID)
+}
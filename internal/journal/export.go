@@ -0,0 +1,91 @@
+package journal
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const dateFormat = "2006-01-02"
+
+// Format returns entries in the format of the extension of path: CSV for .csv, the JSON journal otherwise
+func Format(entries []Entry, path string) ([]byte, error) {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return FormatCSV(entries)
+	}
+	return FormatJSON(entries)
+}
+
+// FormatCSV returns entries as CSV, one row per line of an entry
+func FormatCSV(entries []Entry) ([]byte, error) {
+	rows := [][]string{{"entry_id", "date", "bank_id", "category", "reference", "description", "account", "debit", "credit"}}
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			rows = append(rows, []string{
+				entry.ID, entry.Date.Format(dateFormat), entry.BankID, entry.Category, entry.Reference, entry.Description,
+				line.Account, line.Debit.String(), line.Credit.String(),
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("writing journal CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonJournal is the generic JSON journal: amounts are decimal strings, dates ISO 8601
+type jsonJournal struct {
+	Entries     []jsonEntry `json:"entries"`
+	TotalDebit  string      `json:"total_debit"`
+	TotalCredit string      `json:"total_credit"`
+}
+
+type jsonEntry struct {
+	ID          string     `json:"id"`
+	Date        string     `json:"date"`
+	BankID      string     `json:"bank_id"`
+	Category    string     `json:"category"`
+	Reference   string     `json:"reference"`
+	Description string     `json:"description"`
+	Lines       []jsonLine `json:"lines"`
+}
+
+type jsonLine struct {
+	Account string `json:"account"`
+	Debit   string `json:"debit"`
+	Credit  string `json:"credit"`
+}
+
+// FormatJSON returns entries as the generic JSON journal, with their total debits and credits
+func FormatJSON(entries []Entry) ([]byte, error) {
+	debit, credit := Totals(entries)
+	journal := jsonJournal{
+		Entries:     make([]jsonEntry, 0, len(entries)),
+		TotalDebit:  debit.String(),
+		TotalCredit: credit.String(),
+	}
+
+	for _, entry := range entries {
+		lines := make([]jsonLine, 0, len(entry.Lines))
+		for _, line := range entry.Lines {
+			lines = append(lines, jsonLine{Account: line.Account, Debit: line.Debit.String(), Credit: line.Credit.String()})
+		}
+
+		journal.Entries = append(journal.Entries, jsonEntry{
+			ID:          entry.ID,
+			Date:        entry.Date.Format(dateFormat),
+			BankID:      entry.BankID,
+			Category:    entry.Category,
+			Reference:   entry.Reference,
+			Description: entry.Description,
+			Lines:       lines,
+		})
+	}
+
+	return json.MarshalIndent(journal, "", "  ")
+}
@@ -0,0 +1,130 @@
+package journal
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+// Entry is a proposed journal entry, its lines debiting and crediting the same amount
+type Entry struct {
+	ID          string
+	Date        time.Time
+	BankID      string
+	Category    string
+	Reference   string // Identifiers of the transactions the entry books
+	Description string
+	Lines       []Line
+}
+
+// Line is a line of a journal entry, debiting or crediting an account
+type Line struct {
+	Account string
+	Debit   decimal.Decimal
+	Credit  decimal.Decimal
+}
+
+// Build proposes the journal entries booking the differences of the matched pairs, and the bank transactions
+// missing from the system, so the book agrees with the bank statements. The bank statement is taken as
+// right: an entry moves the difference between the bank account and the account of its category
+func Build(result domain.ReconciliationResult, chart *ChartOfAccounts) ([]Entry, error) {
+	var entries []Entry
+
+	for _, match := range result.MatchedTxns {
+		// The difference of the bank amount to the signed system amount, positive when the bank got more
+		diff := match.BankTxn.Amount.Sub(signedAmount(match.SystemTxn))
+		if diff.IsZero() {
+			continue
+		}
+
+		category := CategoryFXDifference
+		if diff.Abs().LessThanOrEqual(chart.RoundingThreshold) {
+			category = CategoryRounding
+		}
+
+		entry, err := newEntry(chart, match.BankTxn, category, diff)
+		if err != nil {
+			return nil, err
+		}
+		entry.Reference = match.SystemTxn.TrxID + "/" + match.BankTxn.UniqID
+		entry.Description = fmt.Sprintf("%s booked at %s, %s at %s on the bank statement", match.SystemTxn.TrxID, signedAmount(match.SystemTxn), match.BankTxn.UniqID, match.BankTxn.Amount)
+		entries = append(entries, entry)
+	}
+
+	for _, bankID := range slices.Sorted(maps.Keys(result.UnMatchedBankTxns)) {
+		for _, txn := range result.UnMatchedBankTxns[bankID] {
+			if txn.Amount.IsZero() {
+				continue
+			}
+
+			category := CategoryBankCredit
+			if txn.Amount.IsNegative() {
+				category = CategoryBankFee
+			}
+
+			entry, err := newEntry(chart, txn, category, txn.Amount)
+			if err != nil {
+				return nil, err
+			}
+			entry.Reference = txn.UniqID
+			entry.Description = fmt.Sprintf("Bank transaction %s missing from the system", txn.UniqID)
+			entries = append(entries, entry)
+		}
+	}
+
+	for i := range entries {
+		entries[i].ID = fmt.Sprintf("JE-%04d", i+1)
+	}
+
+	return entries, nil
+}
+
+// newEntry returns the entry moving amount into the bank account from the account of category, or out of it
+// when amount is negative
+func newEntry(chart *ChartOfAccounts, txn domain.BankTransaction, category string, amount decimal.Decimal) (Entry, error) {
+	bankAccount, err := chart.Account(txn.BankID, AccountBank)
+	if err != nil {
+		return Entry{}, err
+	}
+	categoryAccount, err := chart.Account(txn.BankID, category)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	debit, credit := bankAccount, categoryAccount
+	if amount.IsNegative() {
+		debit, credit = categoryAccount, bankAccount
+	}
+
+	return Entry{
+		Date:     txn.Date,
+		BankID:   txn.BankID,
+		Category: category,
+		Lines: []Line{
+			{Account: debit, Debit: amount.Abs(), Credit: decimal.Zero},
+			{Account: credit, Debit: decimal.Zero, Credit: amount.Abs()},
+		},
+	}, nil
+}
+
+// Totals returns the total debits and credits of entries, equal when they balance
+func Totals(entries []Entry) (debit, credit decimal.Decimal) {
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			debit = debit.Add(line.Debit)
+			credit = credit.Add(line.Credit)
+		}
+	}
+	return debit, credit
+}
+
+func signedAmount(txn domain.SystemTransaction) decimal.Decimal {
+	if txn.Type == domain.Debit {
+		return txn.Amount.Neg()
+	}
+	return txn.Amount
+}
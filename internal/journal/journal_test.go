@@ -0,0 +1,154 @@
+package journal_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/journal"
+)
+
+const testChart = `{
+  "rounding_threshold": "0.05",
+  "accounts": {"bank": "1000", "rounding": "7900", "fx_difference": "7100", "bank_fee": "6100", "bank_credit": "4900"},
+  "banks": {"bank_b": {"bank": "1020", "bank_fee": "6120"}}
+}`
+
+func loadTestChart(t *testing.T, content string) *journal.ChartOfAccounts {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "chart.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write chart of accounts: %v", err)
+	}
+
+	chart, err := journal.LoadChartOfAccounts(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return chart
+}
+
+func newTestResult() domain.ReconciliationResult {
+	day := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	amount := decimal.RequireFromString
+
+	return domain.ReconciliationResult{
+		MatchedTxns: []domain.Match{
+			{
+				SystemTxn: domain.SystemTransaction{TrxID: "SYS-1", Amount: amount("100.05"), Type: domain.Debit, TransactionTime: day},
+				BankTxn:   domain.BankTransaction{UniqID: "BNK-1", Amount: amount("-100"), Date: day, BankID: "bank_a"},
+			},
+			{
+				SystemTxn: domain.SystemTransaction{TrxID: "SYS-2", Amount: amount("500"), Type: domain.Credit, TransactionTime: day},
+				BankTxn:   domain.BankTransaction{UniqID: "BNK-2", Amount: amount("498.20"), Date: day, BankID: "bank_a"},
+			},
+			{
+				SystemTxn: domain.SystemTransaction{TrxID: "SYS-3", Amount: amount("10"), Type: domain.Credit, TransactionTime: day},
+				BankTxn:   domain.BankTransaction{UniqID: "BNK-3", Amount: amount("10"), Date: day, BankID: "bank_a"},
+			},
+		},
+		UnMatchedBankTxns: map[string][]domain.BankTransaction{
+			"bank_b": {{UniqID: "BNK-9", Amount: amount("-2.50"), Date: day, BankID: "bank_b"}},
+			"bank_a": {{UniqID: "BNK-8", Amount: amount("0.75"), Date: day, BankID: "bank_a"}},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	entries, err := journal.Build(newTestResult(), loadTestChart(t, testChart))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		category string
+		debit    string
+		credit   string
+		amount   string
+	}{
+		{journal.CategoryRounding, "1000", "7900", "0.05"},    // The bank paid out less than booked
+		{journal.CategoryFXDifference, "7100", "1000", "1.8"}, // The bank received less than booked
+		{journal.CategoryBankCredit, "1000", "4900", "0.75"},
+		{journal.CategoryBankFee, "6120", "1020", "2.5"}, // bank_b's own accounts
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("Expected %d entries, got %d", len(tests), len(entries))
+	}
+
+	for i, tt := range tests {
+		entry := entries[i]
+		if entry.Category != tt.category {
+			t.Errorf("Expected entry %d of category %s, got %s", i, tt.category, entry.Category)
+		}
+		if len(entry.Lines) != 2 {
+			t.Fatalf("Expected 2 lines in entry %d, got %d", i, len(entry.Lines))
+		}
+		if entry.Lines[0].Account != tt.debit || !entry.Lines[0].Debit.Equal(decimal.RequireFromString(tt.amount)) {
+			t.Errorf("Expected entry %d to debit %s with %s, got %s with %s", i, tt.debit, tt.amount, entry.Lines[0].Account, entry.Lines[0].Debit)
+		}
+		if entry.Lines[1].Account != tt.credit || !entry.Lines[1].Credit.Equal(decimal.RequireFromString(tt.amount)) {
+			t.Errorf("Expected entry %d to credit %s with %s, got %s with %s", i, tt.credit, tt.amount, entry.Lines[1].Account, entry.Lines[1].Credit)
+		}
+	}
+
+	if entries[0].ID != "JE-0001" || entries[0].Reference != "SYS-1/BNK-1" {
+		t.Errorf("Expected entry JE-0001 referencing SYS-1/BNK-1, got %s referencing %s", entries[0].ID, entries[0].Reference)
+	}
+
+	debit, credit := journal.Totals(entries)
+	if !debit.Equal(credit) {
+		t.Errorf("Expected debits to equal credits, got %s and %s", debit, credit)
+	}
+}
+
+func TestBuild_MissingAccount(t *testing.T) {
+	chart := loadTestChart(t, `{"accounts": {"bank": "1000", "rounding": "7900"}}`)
+
+	_, err := journal.Build(newTestResult(), chart)
+	if err == nil || !strings.Contains(err.Error(), "fx_difference") {
+		t.Errorf("Expected missing fx_difference account error, got %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	entries, err := journal.Build(newTestResult(), loadTestChart(t, testChart))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := journal.Format(entries, "journal.csv")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1+2*len(entries) {
+		t.Errorf("Expected %d CSV rows, got %d", 1+2*len(entries), len(lines))
+	}
+
+	data, err = journal.Format(entries, "journal.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var doc struct {
+		Entries     []json.RawMessage `json:"entries"`
+		TotalDebit  string            `json:"total_debit"`
+		TotalCredit string            `json:"total_credit"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to decode JSON journal: %v", err)
+	}
+	if len(doc.Entries) != len(entries) {
+		t.Errorf("Expected %d entries, got %d", len(entries), len(doc.Entries))
+	}
+	if doc.TotalDebit != "5.1" || doc.TotalCredit != "5.1" {
+		t.Errorf("Expected total debit and credit of 5.1, got %s and %s", doc.TotalDebit, doc.TotalCredit)
+	}
+}
@@ -1,7 +1,10 @@
 package matcher
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"slices"
 
 	"github.com/tirasundara/reconciliation-service/internal/domain"
 )
@@ -33,18 +36,25 @@ func NewDefaultMatcher(strategies ...MatchingStrategy) *DefaultMatcher {
 	}
 }
 
-func (m *DefaultMatcher) FindMatches(systemTxns []domain.SystemTransaction, bankTxns []domain.BankTransaction) ([]domain.Match, error) {
+// FindMatches pairs each system transaction with the first bank transaction accepted by one of the strategies.
+// Matching stops with the context's error once ctx is cancelled.
+func (m *DefaultMatcher) FindMatches(ctx context.Context, systemTxns []domain.SystemTransaction, bankTxns []domain.BankTransaction) ([]domain.Match, error) {
 	matches := make([]domain.Match, 0)
 
 	// Print info
-	fmt.Printf("Matching %d system transactions with %d bank transactions\n", len(systemTxns), len(bankTxns))
+	fmt.Fprintf(os.Stderr, "Matching %d system transactions with %d bank transactions\n", len(systemTxns), len(bankTxns))
 
 	matchedBankTxns := make(map[string]bool)
 
 	// For each system transaction, try to find a match
 	for _, sysTxn := range systemTxns {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		var matched bool
 		var matchedBankTxn domain.BankTransaction
+		var matchedStrategy string
 
 		// Try each strategy in order until a match is found
 		for _, strategy := range m.strategies {
@@ -65,6 +75,7 @@ func (m *DefaultMatcher) FindMatches(systemTxns []domain.SystemTransaction, bank
 			if found {
 				matched = true
 				matchedBankTxn = bankTxn
+				matchedStrategy = strategyName(strategy)
 				break
 			}
 		}
@@ -74,18 +85,43 @@ func (m *DefaultMatcher) FindMatches(systemTxns []domain.SystemTransaction, bank
 			key := fmt.Sprintf("%s-%s", matchedBankTxn.BankID, matchedBankTxn.UniqID)
 			matchedBankTxns[key] = true
 
-			sysAmount := getNormalizedAmount(sysTxn)
-			amountDiff := sysAmount.Sub(matchedBankTxn.Amount).Abs()
+			matches = append(matches, newMatch(sysTxn, matchedBankTxn, matchedStrategy))
+		}
+	}
 
-			match := domain.Match{
-				SystemTxn:   sysTxn,
-				BankTxn:     matchedBankTxn,
-				AmmountDiff: amountDiff,
-			}
+	return matches, nil
+}
 
-			matches = append(matches, match)
+// newMatch pairs a system transaction with the bank transaction the named strategy matched
+func newMatch(sysTxn domain.SystemTransaction, bankTxn domain.BankTransaction, strategy string) domain.Match {
+	sysAmount := getNormalizedAmount(sysTxn)
+	amountDiff := sysAmount.Sub(bankTxn.Amount).Abs()
+
+	return domain.Match{
+		SystemTxn:   sysTxn,
+		BankTxn:     bankTxn,
+		AmmountDiff: amountDiff,
+		Strategy:    strategy,
+	}
+}
+
+// findMatch tries each strategy in order and returns the index of the candidate found, and the name of the
+// strategy that found it
+func findMatch(strategies []MatchingStrategy, sysTxn domain.SystemTransaction, candidates []domain.BankTransaction) (int, string, bool) {
+	for _, strategy := range strategies {
+		bankTxn, found := strategy.Match(sysTxn, candidates)
+		if !found {
+			continue
+		}
+
+		i := slices.IndexFunc(candidates, func(txn domain.BankTransaction) bool {
+			return txn.BankID == bankTxn.BankID && txn.UniqID == bankTxn.UniqID &&
+				txn.Amount.Equal(bankTxn.Amount) && txn.Date.Equal(bankTxn.Date)
+		})
+		if i >= 0 {
+			return i, strategyName(strategy), true
 		}
 	}
 
-	return matches, nil
+	return -1, "", false
 }
@@ -1,6 +1,8 @@
 package matcher_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/shopspring/decimal"
@@ -63,7 +65,7 @@ func TestDefaultMatcher_FindMatches(t *testing.T) {
 	}
 
 	// Find matches
-	matches, err := m.FindMatches(systemTxns, bankTxns)
+	matches, err := m.FindMatches(context.Background(), systemTxns, bankTxns)
 
 	// Check for errors
 	if err != nil {
@@ -103,7 +105,7 @@ func TestDefaultMatcher_FindMatches(t *testing.T) {
 		TransactionTime: parseTime(t, "2025-01-15T16:30:00"), // Same day
 	})
 
-	matches, err = m.FindMatches(systemTxns, bankTxns)
+	matches, err = m.FindMatches(context.Background(), systemTxns, bankTxns)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -155,7 +157,7 @@ func TestDefaultMatcher_WithDiscrepancies(t *testing.T) {
 	}
 
 	// Find matches
-	matches, err := m.FindMatches(systemTxns, bankTxns)
+	matches, err := m.FindMatches(context.Background(), systemTxns, bankTxns)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -172,4 +174,37 @@ func TestDefaultMatcher_WithDiscrepancies(t *testing.T) {
 		t.Errorf("Expected amount difference to be %s, got %s",
 			expectedDiff, matches[0].AmmountDiff)
 	}
+	if matches[0].Strategy != "fuzzy" {
+		t.Errorf("Expected the match to be found by the fuzzy strategy, got %s", matches[0].Strategy)
+	}
+}
+
+func TestDefaultMatcher_CancelledContext(t *testing.T) {
+	m := matcher.NewDefaultMatcher()
+
+	systemTxns := []domain.SystemTransaction{
+		{
+			TrxID:           "SYS-TXN-12345",
+			Amount:          decimal.NewFromFloat(100000.50),
+			Type:            domain.Credit,
+			TransactionTime: parseTime(t, "2025-01-15T14:30:00"),
+		},
+	}
+
+	bankTxns := []domain.BankTransaction{
+		{
+			UniqID: "BANK-STMT-98765",
+			Amount: decimal.NewFromFloat(100000.50),
+			Date:   parseTime(t, "2025-01-15"),
+			BankID: "Bank-ABC",
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := m.FindMatches(ctx, systemTxns, bankTxns)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
 }
@@ -0,0 +1,314 @@
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"golang.org/x/sync/errgroup"
+)
+
+const defaultShardDays = 7
+
+// ParallelMatcher implements the TransactionMatcher interface by matching day shards concurrently.
+//
+// System transactions are split into shards of ShardDays consecutive days. Each shard is matched on its own
+// against the bank transactions of its days plus windowDays on each side, so neighbouring shards overlap.
+// The shard results are then replayed in input order: a result is kept unless another shard consumed or
+// released a bank transaction within the window of that system transaction, in which case it is recomputed.
+// The matches are therefore identical to DefaultMatcher's, whatever the number of workers.
+type ParallelMatcher struct {
+	strategies []MatchingStrategy
+	windowDays int
+
+	ShardDays int // Days of system transactions per shard
+	Workers   int // Number of shards matched concurrently
+}
+
+// NewParallelMatcher creates a new ParallelMatcher with the given strategies.
+// windowDays is how many days apart a system and a bank transaction can still match: it must cover
+// the widest strategy, e.g. the buffer of a DateBufferMatchStrategy. workers defaults to the number of CPUs.
+func NewParallelMatcher(windowDays, workers int, strategies ...MatchingStrategy) *ParallelMatcher {
+	if len(strategies) == 0 {
+
+		// Default strategies
+		strategies = []MatchingStrategy{
+			NewExactMatchStrategy(),
+			NewFuzzyMatchStrategy(defaultAmountThreshold),
+			NewDateBufferMatchStrategy(defaultDaysBuffer),
+		}
+		windowDays = max(windowDays, defaultDaysBuffer)
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	return &ParallelMatcher{
+		strategies: strategies,
+		windowDays: windowDays,
+		ShardDays:  max(defaultShardDays, 2*windowDays+1),
+		Workers:    workers,
+	}
+}
+
+// shard is a set of consecutive days of system transactions, with the bank transactions they can match
+type shard struct {
+	sys   []int // Indices of the system transactions, in input order
+	bank  []int // Indices of the bank transactions within the window of the shard's days, in input order
+	local []int // Bank transaction matched by each system transaction when the shard is matched on its own, -1 if none
+
+	strategies []string // Strategy of each local match
+}
+
+// FindMatches implements the TransactionMatcher interface
+func (m *ParallelMatcher) FindMatches(ctx context.Context, systemTxns []domain.SystemTransaction, bankTxns []domain.BankTransaction) ([]domain.Match, error) {
+	if len(systemTxns) == 0 {
+		return []domain.Match{}, nil
+	}
+
+	shards, firstDay := m.shard(systemTxns, bankTxns)
+
+	// Match every shard on its own
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(m.Workers)
+
+	for _, sh := range shards {
+		if len(sh.sys) == 0 {
+			continue
+		}
+
+		g.Go(func() error {
+			return m.matchShard(gctx, sh, systemTxns, bankTxns)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return m.resolve(ctx, shards, firstDay, systemTxns, bankTxns)
+}
+
+// shard splits the transactions into shards, and returns them with the first day of the first shard
+func (m *ParallelMatcher) shard(systemTxns []domain.SystemTransaction, bankTxns []domain.BankTransaction) ([]*shard, time.Time) {
+	firstDay, lastDay := dayOf(systemTxns[0].TransactionTime), dayOf(systemTxns[0].TransactionTime)
+	for _, txn := range systemTxns {
+		day := dayOf(txn.TransactionTime)
+		if day.Before(firstDay) {
+			firstDay = day
+		}
+		if day.After(lastDay) {
+			lastDay = day
+		}
+	}
+
+	shards := make([]*shard, m.shardOf(lastDay, firstDay)+1)
+	for i := range shards {
+		shards[i] = &shard{}
+	}
+
+	for i, txn := range systemTxns {
+		sh := shards[m.shardOf(dayOf(txn.TransactionTime), firstDay)]
+		sh.sys = append(sh.sys, i)
+	}
+
+	for i, txn := range bankTxns {
+		for _, s := range m.shardsCovering(dayOf(txn.Date), firstDay, len(shards)) {
+			shards[s].bank = append(shards[s].bank, i)
+		}
+	}
+
+	return shards, firstDay
+}
+
+// shardOf returns the shard holding the system transactions of day
+func (m *ParallelMatcher) shardOf(day, firstDay time.Time) int {
+	return int(day.Sub(firstDay).Hours()/24) / m.ShardDays
+}
+
+// shardsCovering returns the shards whose window includes a bank transaction of day
+func (m *ParallelMatcher) shardsCovering(day, firstDay time.Time, numShards int) []int {
+	from := int(day.AddDate(0, 0, -m.windowDays).Sub(firstDay).Hours() / 24)
+	to := int(day.AddDate(0, 0, m.windowDays).Sub(firstDay).Hours() / 24)
+	if to < 0 {
+		return nil
+	}
+
+	fromShard := max(0, floorDiv(from, m.ShardDays))
+	toShard := min(numShards-1, to/m.ShardDays)
+
+	var shards []int
+	for s := fromShard; s <= toShard; s++ {
+		shards = append(shards, s)
+	}
+	return shards
+}
+
+// matchShard matches the system transactions of a shard in input order, like DefaultMatcher does for all of them
+func (m *ParallelMatcher) matchShard(ctx context.Context, sh *shard, systemTxns []domain.SystemTransaction, bankTxns []domain.BankTransaction) error {
+	sh.local = make([]int, len(sh.sys))
+	sh.strategies = make([]string, len(sh.sys))
+	consumed := make(map[string]bool)
+	candidates := make([]domain.BankTransaction, 0, len(sh.bank))
+	candidateIdx := make([]int, 0, len(sh.bank))
+
+	for i, sysIdx := range sh.sys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		candidates, candidateIdx = candidates[:0], candidateIdx[:0]
+		for _, bankIdx := range sh.bank {
+			if !consumed[bankKey(bankTxns[bankIdx])] {
+				candidates = append(candidates, bankTxns[bankIdx])
+				candidateIdx = append(candidateIdx, bankIdx)
+			}
+		}
+
+		sh.local[i] = -1
+		if c, strategy, found := findMatch(m.strategies, systemTxns[sysIdx], candidates); found {
+			sh.local[i], sh.strategies[i] = candidateIdx[c], strategy
+			consumed[bankKey(bankTxns[candidateIdx[c]])] = true
+		}
+	}
+
+	return nil
+}
+
+// resolve replays the shard results in input order and recomputes the ones another shard interfered with
+func (m *ParallelMatcher) resolve(
+	ctx context.Context,
+	shards []*shard,
+	firstDay time.Time,
+	systemTxns []domain.SystemTransaction,
+	bankTxns []domain.BankTransaction,
+) ([]domain.Match, error) {
+
+	// Position of every system transaction within its shard
+	type position struct {
+		shard *shard
+		index int
+	}
+	positions := make([]position, len(systemTxns))
+	for _, sh := range shards {
+		for i, sysIdx := range sh.sys {
+			positions[sysIdx] = position{shard: sh, index: i}
+		}
+	}
+
+	// Days and shards of the bank transactions sharing a key, a key is consumed as a whole
+	keyDays := make(map[string][]time.Time)
+	keyShards := make(map[string]map[*shard]bool)
+	for _, txn := range bankTxns {
+		key := bankKey(txn)
+		keyDays[key] = append(keyDays[key], dayOf(txn.Date))
+		if keyShards[key] == nil {
+			keyShards[key] = make(map[*shard]bool)
+		}
+		for _, s := range m.shardsCovering(dayOf(txn.Date), firstDay, len(shards)) {
+			keyShards[key][shards[s]] = true
+		}
+	}
+
+	// For every shard, the keys consumed so far by its own replay, and the keys whose state differs
+	// between that replay and the global one. A local result stays valid as long as none of those
+	// keys is within the window of the system transaction.
+	global := make(map[string]bool)
+	local := make(map[*shard]map[string]bool)
+	diff := make(map[*shard]map[string]bool)
+	for _, sh := range shards {
+		local[sh] = make(map[string]bool)
+		diff[sh] = make(map[string]bool)
+	}
+
+	refresh := func(sh *shard, key string) {
+		if global[key] != local[sh][key] {
+			diff[sh][key] = true
+		} else {
+			delete(diff[sh], key)
+		}
+	}
+
+	matches := make([]domain.Match, 0)
+
+	for sysIdx, sysTxn := range systemTxns {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		pos := positions[sysIdx]
+		sh := pos.shard
+		localIdx := sh.local[pos.index]
+
+		day := dayOf(sysTxn.TransactionTime)
+		minDay, maxDay := day.AddDate(0, 0, -m.windowDays), day.AddDate(0, 0, m.windowDays)
+
+		valid := true
+		for key := range diff[sh] {
+			for _, d := range keyDays[key] {
+				if !d.Before(minDay) && !d.After(maxDay) {
+					valid = false
+				}
+			}
+		}
+
+		matchedIdx, strategy := localIdx, sh.strategies[pos.index]
+		if !valid {
+			matchedIdx, strategy = m.recompute(sysTxn, sh, global, bankTxns)
+		}
+
+		// Replay the shard's own result, then record the actual one
+		if localIdx >= 0 {
+			key := bankKey(bankTxns[localIdx])
+			local[sh][key] = true
+			refresh(sh, key)
+		}
+
+		if matchedIdx >= 0 {
+			bankTxn := bankTxns[matchedIdx]
+			key := bankKey(bankTxn)
+			global[key] = true
+			for other := range keyShards[key] {
+				refresh(other, key)
+			}
+
+			matches = append(matches, newMatch(sysTxn, bankTxn, strategy))
+		}
+	}
+
+	return matches, nil
+}
+
+// recompute matches a system transaction against the bank transactions of its shard not consumed globally
+func (m *ParallelMatcher) recompute(sysTxn domain.SystemTransaction, sh *shard, global map[string]bool, bankTxns []domain.BankTransaction) (int, string) {
+	candidates := make([]domain.BankTransaction, 0, len(sh.bank))
+	candidateIdx := make([]int, 0, len(sh.bank))
+	for _, bankIdx := range sh.bank {
+		if !global[bankKey(bankTxns[bankIdx])] {
+			candidates = append(candidates, bankTxns[bankIdx])
+			candidateIdx = append(candidateIdx, bankIdx)
+		}
+	}
+
+	if c, strategy, found := findMatch(m.strategies, sysTxn, candidates); found {
+		return candidateIdx[c], strategy
+	}
+	return -1, ""
+}
+
+// bankKey identifies a bank transaction, like DefaultMatcher does
+func bankKey(txn domain.BankTransaction) string {
+	return fmt.Sprintf("%s-%s", txn.BankID, txn.UniqID)
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
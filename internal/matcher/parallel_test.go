@@ -0,0 +1,113 @@
+package matcher_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/matcher"
+)
+
+func TestParallelMatcher_MatchesLikeDefaultMatcher(t *testing.T) {
+	systemTxns, bankTxns := generateTransactions(42, 24, 10)
+
+	// Shuffled inputs put matches of neighbouring shards in competition
+	shuffledSys := slices.Clone(systemTxns)
+	shuffledBank := slices.Clone(bankTxns)
+	rng := rand.New(rand.NewSource(3))
+	rng.Shuffle(len(shuffledSys), func(i, j int) { shuffledSys[i], shuffledSys[j] = shuffledSys[j], shuffledSys[i] })
+	rng.Shuffle(len(shuffledBank), func(i, j int) { shuffledBank[i], shuffledBank[j] = shuffledBank[j], shuffledBank[i] })
+
+	strategies := []matcher.MatchingStrategy{
+		matcher.NewExactMatchStrategy(),
+		matcher.NewFuzzyMatchStrategy(0.10),
+		matcher.NewDateBufferMatchStrategy(2),
+	}
+
+	inputs := []struct {
+		name       string
+		systemTxns []domain.SystemTransaction
+		bankTxns   []domain.BankTransaction
+	}{
+		{"sorted", systemTxns, bankTxns},
+		{"shuffled", shuffledSys, shuffledBank},
+	}
+
+	for _, input := range inputs {
+		expected, err := matcher.NewDefaultMatcher(strategies...).FindMatches(context.Background(), input.systemTxns, input.bankTxns)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, workers := range []int{1, 2, 4, 8} {
+			for _, shardDays := range []int{1, 3, 7} {
+				t.Run(fmt.Sprintf("%s/workers=%d/shard=%d", input.name, workers, shardDays), func(t *testing.T) {
+					m := matcher.NewParallelMatcher(2, workers, strategies...)
+					m.ShardDays = shardDays
+
+					matches, err := m.FindMatches(context.Background(), input.systemTxns, input.bankTxns)
+					if err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+
+					if orderedMatchKeys(matches) != orderedMatchKeys(expected) {
+						t.Errorf("Expected the same %d matches as DefaultMatcher in the same order, got %d (%d in common)",
+							len(expected), len(matches), commonMatches(matches, expected))
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestParallelMatcher_Empty(t *testing.T) {
+	matches, err := matcher.NewParallelMatcher(1, 4).FindMatches(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(matches) != 0 {
+		t.Errorf("Expected no matches, got %d", len(matches))
+	}
+}
+
+func TestParallelMatcher_Cancelled(t *testing.T) {
+	systemTxns, bankTxns := generateTransactions(7, 30, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := matcher.NewParallelMatcher(1, 4).FindMatches(ctx, systemTxns, bankTxns)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// orderedMatchKeys renders matches as a comparable string, keeping their order
+func orderedMatchKeys(matches []domain.Match) string {
+	keys := make([]string, 0, len(matches))
+	for _, match := range matches {
+		keys = append(keys, fmt.Sprintf("%s=%s/%s:%s(%s)", match.SystemTxn.TrxID, match.BankTxn.BankID, match.BankTxn.UniqID, match.AmmountDiff, match.Strategy))
+	}
+	return fmt.Sprint(keys)
+}
+
+// commonMatches counts the system transactions matched to the same bank transaction in both results
+func commonMatches(a, b []domain.Match) int {
+	pairs := make(map[string]bool)
+	for _, match := range a {
+		pairs[match.SystemTxn.TrxID+"="+match.BankTxn.UniqID] = true
+	}
+
+	common := 0
+	for _, match := range b {
+		if pairs[match.SystemTxn.TrxID+"="+match.BankTxn.UniqID] {
+			common++
+		}
+	}
+	return common
+}
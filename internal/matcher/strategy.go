@@ -1,6 +1,7 @@
 package matcher
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -12,6 +13,21 @@ type MatchingStrategy interface {
 	Match(sysTxn domain.SystemTransaction, bankTxns []domain.BankTransaction) (domain.BankTransaction, bool)
 }
 
+// NamedStrategy is a MatchingStrategy with a name, reported in the matches it finds.
+// The matches of strategies without a name report their type instead
+type NamedStrategy interface {
+	MatchingStrategy
+	Name() string
+}
+
+// strategyName returns the name reported in the matches found by strategy
+func strategyName(strategy MatchingStrategy) string {
+	if named, ok := strategy.(NamedStrategy); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", strategy)
+}
+
 // ExactMatchStrategy matches transactions based on exact date and amount
 type ExactMatchStrategy struct{}
 
@@ -20,6 +36,10 @@ func NewExactMatchStrategy() *ExactMatchStrategy {
 	return &ExactMatchStrategy{}
 }
 
+func (s *ExactMatchStrategy) Name() string {
+	return "exact"
+}
+
 // Match implements the MatchingStrategy interface
 func (s *ExactMatchStrategy) Match(sysTxn domain.SystemTransaction, bankTxns []domain.BankTransaction) (domain.BankTransaction, bool) {
 	sysTxnDate := sysTxn.TransactionTime.Truncate(24 * time.Hour)
@@ -56,6 +76,10 @@ func NewFuzzyMatchStrategy(threshold float64) *FuzzyMatchStrategy {
 	}
 }
 
+func (s *FuzzyMatchStrategy) Name() string {
+	return "fuzzy"
+}
+
 // Match implements the MatchingStrategy interface
 func (s *FuzzyMatchStrategy) Match(sysTxn domain.SystemTransaction, bankTxns []domain.BankTransaction) (domain.BankTransaction, bool) {
 	sysTxnDate := sysTxn.TransactionTime.Truncate(24 * time.Hour)
@@ -93,6 +117,10 @@ func NewDateBufferMatchStrategy(bufferDays int) *DateBufferMatchStrategy {
 	}
 }
 
+func (s *DateBufferMatchStrategy) Name() string {
+	return "date_buffer"
+}
+
 // Match implements the MatchingStrategy interface
 func (s *DateBufferMatchStrategy) Match(sysTxn domain.SystemTransaction, bankTxns []domain.BankTransaction) (domain.BankTransaction, bool) {
 	sysAmount := getNormalizedAmount(sysTxn)
@@ -0,0 +1,311 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+// ErrUnsortedStream is returned when a transaction stream isn't sorted by date
+var ErrUnsortedStream = errors.New("transaction stream is not sorted by date")
+
+// StreamMatcher implements the StreamingTransactionMatcher interface.
+// It walks date-sorted streams one system day at a time and only holds the bank transactions of the days
+// a system transaction of that day can match, so memory is bounded by the window instead of the inputs.
+type StreamMatcher struct {
+	strategies []MatchingStrategy
+	windowDays int
+}
+
+// NewStreamMatcher creates a new StreamMatcher with the given strategies.
+// windowDays is how many days apart a system and a bank transaction can still match: it must cover
+// the widest strategy, e.g. the buffer of a DateBufferMatchStrategy.
+func NewStreamMatcher(windowDays int, strategies ...MatchingStrategy) *StreamMatcher {
+	if len(strategies) == 0 {
+
+		// Default strategies
+		strategies = []MatchingStrategy{
+			NewExactMatchStrategy(),
+			NewFuzzyMatchStrategy(defaultAmountThreshold),
+			NewDateBufferMatchStrategy(defaultDaysBuffer),
+		}
+		windowDays = max(windowDays, defaultDaysBuffer)
+	}
+
+	return &StreamMatcher{
+		strategies: strategies,
+		windowDays: windowDays,
+	}
+}
+
+// MatchStreams matches system transactions with the bank transactions of bankStreams, one stream per source, all
+// sorted by date. The candidates of a system transaction are ordered by source, then by position in the source, as
+// for a matcher given the sources one after the other, so both find the same matches.
+// Every system transaction is reported to sink as matched or unmatched once its day is processed, and every
+// bank transaction as unmatched once it falls out of the window.
+func (m *StreamMatcher) MatchStreams(
+	ctx context.Context,
+	systemTxns iter.Seq2[domain.SystemTransaction, error],
+	bankStreams []iter.Seq2[domain.BankTransaction, error],
+	sink domain.MatchSink,
+) error {
+	sys := newCursor(systemTxns, func(txn domain.SystemTransaction) time.Time { return dayOf(txn.TransactionTime) })
+	defer sys.stop()
+
+	// Unmatched bank transactions around the current system day, per source and in stream order
+	sources := make([]*bankSource, 0, len(bankStreams))
+	for _, stream := range bankStreams {
+		source := &bankSource{cursor: newCursor(stream, func(txn domain.BankTransaction) time.Time { return dayOf(txn.Date) })}
+		defer source.cursor.stop()
+		sources = append(sources, source)
+	}
+
+	var dayTxns []domain.SystemTransaction
+	var candidates []domain.BankTransaction
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Take every system transaction of the next day
+		first, ok, err := sys.peek()
+		if err != nil {
+			return fmt.Errorf("reading system transactions: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		day := dayOf(first.TransactionTime)
+		dayTxns = dayTxns[:0]
+		for {
+			txn, ok, err := sys.peek()
+			if err != nil {
+				return fmt.Errorf("reading system transactions: %w", err)
+			}
+			if !ok || !dayOf(txn.TransactionTime).Equal(day) {
+				break
+			}
+
+			dayTxns = append(dayTxns, txn)
+			sys.take()
+		}
+
+		for _, source := range sources {
+			// Load the bank transactions up to the last day of the window
+			if err := source.load(day.AddDate(0, 0, m.windowDays)); err != nil {
+				return fmt.Errorf("reading bank transactions: %w", err)
+			}
+
+			// Bank transactions before the first day of the window can't match anymore
+			if err := source.evict(day.AddDate(0, 0, -m.windowDays), sink); err != nil {
+				return err
+			}
+		}
+
+		for _, sysTxn := range dayTxns {
+			candidates = candidates[:0]
+			for _, source := range sources {
+				candidates = append(candidates, source.window...)
+			}
+
+			i, strategy, found := findMatch(m.strategies, sysTxn, candidates)
+			if !found {
+				if err := sink.UnmatchedSystem(sysTxn); err != nil {
+					return err
+				}
+				continue
+			}
+
+			bankTxn := takeCandidate(sources, i)
+			if err := sink.Matched(newMatch(sysTxn, bankTxn, strategy)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Whatever is left in the windows or the streams never matched
+	for _, source := range sources {
+		if err := source.drain(sink); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bankSource is a bank transaction stream and the window of its transactions still open to a match
+type bankSource struct {
+	cursor *cursor[domain.BankTransaction]
+	window []domain.BankTransaction
+}
+
+// load moves the transactions of the stream up to lastDay into the window
+func (s *bankSource) load(lastDay time.Time) error {
+	for {
+		txn, ok, err := s.cursor.peek()
+		if err != nil {
+			return err
+		}
+		if !ok || dayOf(txn.Date).After(lastDay) {
+			return nil
+		}
+
+		s.window = append(s.window, txn)
+		s.cursor.take()
+	}
+}
+
+// evict reports the transactions of the window before firstDay as unmatched, and drops them
+func (s *bankSource) evict(firstDay time.Time, sink domain.MatchSink) error {
+	kept := s.window[:0]
+	for _, txn := range s.window {
+		if dayOf(txn.Date).Before(firstDay) {
+			if err := sink.UnmatchedBank(txn); err != nil {
+				return err
+			}
+			continue
+		}
+		kept = append(kept, txn)
+	}
+	s.window = kept
+	return nil
+}
+
+// drain reports the transactions left in the window and the stream as unmatched
+func (s *bankSource) drain(sink domain.MatchSink) error {
+	for _, txn := range s.window {
+		if err := sink.UnmatchedBank(txn); err != nil {
+			return err
+		}
+	}
+	s.window = nil
+
+	for {
+		txn, ok, err := s.cursor.peek()
+		if err != nil {
+			return fmt.Errorf("reading bank transactions: %w", err)
+		}
+		if !ok {
+			return nil
+		}
+
+		if err := sink.UnmatchedBank(txn); err != nil {
+			return err
+		}
+		s.cursor.take()
+	}
+}
+
+// takeCandidate removes the i-th candidate, counted across the windows of the sources in order, and returns it
+func takeCandidate(sources []*bankSource, i int) domain.BankTransaction {
+	for _, source := range sources {
+		if i < len(source.window) {
+			txn := source.window[i]
+			source.window = slices.Delete(source.window, i, i+1)
+			return txn
+		}
+		i -= len(source.window)
+	}
+	return domain.BankTransaction{}
+}
+
+// FindMatches implements the TransactionMatcher interface, by streaming copies of the transactions sorted by date
+func (m *StreamMatcher) FindMatches(ctx context.Context, systemTxns []domain.SystemTransaction, bankTxns []domain.BankTransaction) ([]domain.Match, error) {
+	sortedSys := slices.Clone(systemTxns)
+	slices.SortStableFunc(sortedSys, func(a, b domain.SystemTransaction) int {
+		return dayOf(a.TransactionTime).Compare(dayOf(b.TransactionTime))
+	})
+
+	sortedBank := slices.Clone(bankTxns)
+	slices.SortStableFunc(sortedBank, func(a, b domain.BankTransaction) int {
+		return dayOf(a.Date).Compare(dayOf(b.Date))
+	})
+
+	collector := &matchCollector{}
+	if err := m.MatchStreams(ctx, sliceSeq(sortedSys), []iter.Seq2[domain.BankTransaction, error]{sliceSeq(sortedBank)}, collector); err != nil {
+		return nil, err
+	}
+
+	return collector.matches, nil
+}
+
+// matchCollector is a MatchSink keeping only the matches
+type matchCollector struct {
+	matches []domain.Match
+}
+
+func (c *matchCollector) Matched(match domain.Match) error {
+	c.matches = append(c.matches, match)
+	return nil
+}
+
+func (c *matchCollector) UnmatchedSystem(domain.SystemTransaction) error { return nil }
+
+func (c *matchCollector) UnmatchedBank(domain.BankTransaction) error { return nil }
+
+// cursor pulls transactions from a stream one at a time, and checks they come sorted by day
+type cursor[T any] struct {
+	next    func() (T, error, bool)
+	stop    func()
+	dayOf   func(T) time.Time
+	head    T
+	hasHead bool
+	lastDay time.Time
+}
+
+func newCursor[T any](seq iter.Seq2[T, error], dayOf func(T) time.Time) *cursor[T] {
+	next, stop := iter.Pull2(seq)
+	return &cursor[T]{next: next, stop: stop, dayOf: dayOf}
+}
+
+// peek returns the next transaction without consuming it, ok is false at the end of the stream
+func (c *cursor[T]) peek() (txn T, ok bool, err error) {
+	if c.hasHead {
+		return c.head, true, nil
+	}
+
+	txn, err, ok = c.next()
+	if !ok || err != nil {
+		var zero T
+		return zero, false, err
+	}
+
+	day := c.dayOf(txn)
+	if day.Before(c.lastDay) {
+		var zero T
+		return zero, false, fmt.Errorf("%w: %s after %s", ErrUnsortedStream, day.Format("2006-01-02"), c.lastDay.Format("2006-01-02"))
+	}
+
+	c.lastDay = day
+	c.head, c.hasHead = txn, true
+	return txn, true, nil
+}
+
+// take consumes the transaction returned by peek
+func (c *cursor[T]) take() {
+	var zero T
+	c.head, c.hasHead = zero, false
+}
+
+// sliceSeq yields the elements of a slice with a nil error
+func sliceSeq[T any](s []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, v := range s {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// dayOf returns the day t falls on
+func dayOf(t time.Time) time.Time {
+	return t.Truncate(24 * time.Hour)
+}
@@ -0,0 +1,211 @@
+package matcher_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/matcher"
+)
+
+func TestStreamMatcher_MatchesLikeDefaultMatcher(t *testing.T) {
+	systemTxns, bankTxns := generateTransactions(42, 40, 12)
+
+	strategies := []matcher.MatchingStrategy{
+		matcher.NewExactMatchStrategy(),
+		matcher.NewFuzzyMatchStrategy(0.10),
+		matcher.NewDateBufferMatchStrategy(2),
+	}
+
+	expected, err := matcher.NewDefaultMatcher(strategies...).FindMatches(context.Background(), systemTxns, bankTxns)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sink := &recordingSink{}
+	err = matcher.NewStreamMatcher(2, strategies...).MatchStreams(context.Background(), seqOf(systemTxns), []iter.Seq2[domain.BankTransaction, error]{seqOf(bankTxns)}, sink)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if matchKeys(sink.matches) != matchKeys(expected) {
+		t.Errorf("Expected the stream matcher to find the same %d matches, got %d", len(expected), len(sink.matches))
+	}
+
+	// Every transaction is reported exactly once
+	if len(sink.matches)+len(sink.unmatchedSystem) != len(systemTxns) {
+		t.Errorf("Expected %d system transactions to be reported, got %d",
+			len(systemTxns), len(sink.matches)+len(sink.unmatchedSystem))
+	}
+
+	if len(sink.matches)+len(sink.unmatchedBank) != len(bankTxns) {
+		t.Errorf("Expected %d bank transactions to be reported, got %d",
+			len(bankTxns), len(sink.matches)+len(sink.unmatchedBank))
+	}
+}
+
+func TestStreamMatcher_FindMatches(t *testing.T) {
+	systemTxns, bankTxns := generateTransactions(7, 30, 10)
+
+	// FindMatches sorts its inputs, so shuffled transactions give the same matches
+	shuffledSys := slices.Clone(systemTxns)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffledSys), func(i, j int) {
+		shuffledSys[i], shuffledSys[j] = shuffledSys[j], shuffledSys[i]
+	})
+
+	m := matcher.NewStreamMatcher(1)
+
+	sorted, err := m.FindMatches(context.Background(), systemTxns, bankTxns)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	shuffled, err := m.FindMatches(context.Background(), shuffledSys, bankTxns)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(sorted) == 0 || len(sorted) != len(shuffled) {
+		t.Errorf("Expected the same number of matches, got %d and %d", len(sorted), len(shuffled))
+	}
+}
+
+func TestStreamMatcher_UnsortedStream(t *testing.T) {
+	systemTxns := []domain.SystemTransaction{
+		{TrxID: "SYS-2", Amount: decimal.NewFromInt(10), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-16T10:00:00")},
+		{TrxID: "SYS-1", Amount: decimal.NewFromInt(10), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-15T10:00:00")},
+	}
+
+	err := matcher.NewStreamMatcher(1).MatchStreams(context.Background(), seqOf(systemTxns), []iter.Seq2[domain.BankTransaction, error]{seqOf([]domain.BankTransaction{})}, &recordingSink{})
+	if !errors.Is(err, matcher.ErrUnsortedStream) {
+		t.Errorf("Expected ErrUnsortedStream, got %v", err)
+	}
+}
+
+func TestStreamMatcher_StreamError(t *testing.T) {
+	readErr := errors.New("disk on fire")
+	bankTxns := func(yield func(domain.BankTransaction, error) bool) {
+		yield(domain.BankTransaction{}, readErr)
+	}
+
+	systemTxns := []domain.SystemTransaction{
+		{TrxID: "SYS-1", Amount: decimal.NewFromInt(10), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-15T10:00:00")},
+	}
+
+	err := matcher.NewStreamMatcher(1).MatchStreams(context.Background(), seqOf(systemTxns), []iter.Seq2[domain.BankTransaction, error]{bankTxns}, &recordingSink{})
+	if !errors.Is(err, readErr) {
+		t.Errorf("Expected the stream error, got %v", err)
+	}
+}
+
+// recordingSink is a MatchSink recording everything it receives
+type recordingSink struct {
+	matches         []domain.Match
+	unmatchedSystem []domain.SystemTransaction
+	unmatchedBank   []domain.BankTransaction
+}
+
+func (s *recordingSink) Matched(match domain.Match) error {
+	s.matches = append(s.matches, match)
+	return nil
+}
+
+func (s *recordingSink) UnmatchedSystem(txn domain.SystemTransaction) error {
+	s.unmatchedSystem = append(s.unmatchedSystem, txn)
+	return nil
+}
+
+func (s *recordingSink) UnmatchedBank(txn domain.BankTransaction) error {
+	s.unmatchedBank = append(s.unmatchedBank, txn)
+	return nil
+}
+
+// generateTransactions returns date-sorted system and bank transactions over the given number of days.
+// They mix exact matches, small discrepancies, bank transactions booked a day or two later and unmatched ones,
+// with many identical amounts so that strategies compete for the same bank transactions.
+func generateTransactions(seed int64, days, perDay int) ([]domain.SystemTransaction, []domain.BankTransaction) {
+	rng := rand.New(rand.NewSource(seed))
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var systemTxns []domain.SystemTransaction
+	bankByDay := make([][]domain.BankTransaction, days+3)
+
+	for day := 0; day < days; day++ {
+		for i := 0; i < perDay; i++ {
+			amount := decimal.NewFromInt(int64(rng.Intn(20)+1) * 100)
+			txnType := domain.Credit
+			signed := amount
+			if rng.Intn(2) == 0 {
+				txnType = domain.Debit
+				signed = amount.Neg()
+			}
+
+			systemTxns = append(systemTxns, domain.SystemTransaction{
+				TrxID:           fmt.Sprintf("SYS-%03d-%03d", day, i),
+				Amount:          amount,
+				Type:            txnType,
+				TransactionTime: start.AddDate(0, 0, day).Add(time.Duration(i) * time.Minute),
+			})
+
+			bankDay := day
+			switch rng.Intn(6) {
+			case 0:
+				continue // no bank transaction at all
+			case 1:
+				signed = signed.Add(decimal.NewFromFloat(0.05)) // small discrepancy
+			case 2:
+				bankDay += rng.Intn(3) // booked up to two days later
+			}
+
+			bankByDay[bankDay] = append(bankByDay[bankDay], domain.BankTransaction{
+				UniqID: fmt.Sprintf("BNK-%03d-%03d", day, i),
+				Amount: signed,
+				Date:   start.AddDate(0, 0, bankDay),
+				BankID: "Bank-ABC",
+			})
+		}
+
+		// Bank-only transactions
+		bankByDay[day] = append(bankByDay[day], domain.BankTransaction{
+			UniqID: fmt.Sprintf("BNK-%03d-FEE", day),
+			Amount: decimal.NewFromInt(-100),
+			Date:   start.AddDate(0, 0, day),
+			BankID: "Bank-ABC",
+		})
+	}
+
+	var bankTxns []domain.BankTransaction
+	for _, txns := range bankByDay {
+		bankTxns = append(bankTxns, txns...)
+	}
+
+	return systemTxns, bankTxns
+}
+
+// seqOf yields the elements of a slice with a nil error
+func seqOf[T any](s []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, v := range s {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// matchKeys renders matches as a comparable string, independent of their order
+func matchKeys(matches []domain.Match) string {
+	keys := make([]string, 0, len(matches))
+	for _, match := range matches {
+		keys = append(keys, fmt.Sprintf("%s=%s/%s:%s", match.SystemTxn.TrxID, match.BankTxn.BankID, match.BankTxn.UniqID, match.AmmountDiff))
+	}
+	slices.Sort(keys)
+	return fmt.Sprint(keys)
+}
@@ -0,0 +1,231 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+const (
+	csvTimeFormat = "2006-01-02T15:04:05"
+	csvDateFormat = "2006-01-02"
+)
+
+// CSVFormatter formats reconciliation results as CSV files, one per section: the matched pairs, the unmatched
+// system and bank transactions, the summary and the aging, and the carried forward open items and the balances
+// when the result has them. The unmatched transactions have the columns of the input files, so they can be
+// reconciled again
+type CSVFormatter struct{}
+
+func NewCSVFormatter() *CSVFormatter {
+	return &CSVFormatter{}
+}
+
+// Format implements the OutputFormatter interface, returning the CSV files in a zip archive
+func (f *CSVFormatter) Format(result domain.ReconciliationResult) ([]byte, error) {
+	files, err := f.FormatFiles(result)
+	if err != nil {
+		return nil, err
+	}
+	return ZipFiles(files)
+}
+
+func (f *CSVFormatter) FileExtension() string {
+	return "zip"
+}
+
+// FormatFiles implements the MultiFileFormatter interface
+func (f *CSVFormatter) FormatFiles(result domain.ReconciliationResult) ([]OutputFile, error) {
+	sections := []csvSection{
+		{"summary.csv", summaryRows(result)},
+		{"matched.csv", matchedRows(result.MatchedTxns)},
+		{"unmatched_system.csv", unmatchedSystemRows(result.UnMatchedSystemTxns)},
+		{"unmatched_bank.csv", unmatchedBankRows(result.UnMatchedBankTxns)},
+		{"aging.csv", agingRows(result.Aging)},
+	}
+	if carried := result.CarriedForward; carried != nil {
+		sections = append(sections,
+			csvSection{"cleared_open_items.csv", matchedRows(carried.Cleared)},
+			csvSection{"open_items.csv", openItemRows(*carried)},
+		)
+	}
+	if balances := result.Balances; balances != nil {
+		sections = append(sections, csvSection{"balances.csv", balanceRows(*balances)})
+	}
+
+	files := make([]OutputFile, 0, len(sections))
+	for _, section := range sections {
+		data, err := writeCSV(section.rows)
+		if err != nil {
+			return nil, fmt.Errorf("formatting %s: %w", section.name, err)
+		}
+		files = append(files, OutputFile{Name: section.name, Data: data})
+	}
+
+	return files, nil
+}
+
+// csvSection is a file of the CSV output
+type csvSection struct {
+	name string
+	rows [][]string
+}
+
+func writeCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// summaryRows lists the totals of the summary, keyed by bank or strategy where they're broken down
+func summaryRows(result domain.ReconciliationResult) [][]string {
+	summary := result.Summary
+	rows := [][]string{{"metric", "key", "count", "amount"}}
+
+	addTotal := func(metric, key string, total domain.Total) {
+		rows = append(rows, []string{metric, key, strconv.Itoa(total.Count), total.Amount.String()})
+	}
+	addTotals := func(metric string, totals map[string]domain.Total) {
+		for _, key := range slices.Sorted(maps.Keys(totals)) {
+			addTotal(metric, key, totals[key])
+		}
+	}
+
+	addTotal("system_transactions", "", summary.SystemTxns)
+	addTotals("bank_transactions", summary.BankTxns)
+	addTotal("matched", "", summary.Matched)
+	addTotals("matched_by_strategy", summary.MatchedByStrategy)
+	addTotal("cleared_open_items", "", summary.ClearedOpenItems)
+	addTotal("unmatched_system", "", summary.UnmatchedSystem)
+	addTotals("unmatched_bank", summary.UnmatchedBank)
+
+	rows = append(rows,
+		[]string{"total_transactions_processed", "", strconv.Itoa(result.TotalTxnsProcessed), ""},
+		[]string{"total_discrepancies", "", "", result.TotalDiscrepancies.String()},
+		[]string{"net_unexplained_difference", "", "", summary.NetUnexplainedDifference.String()},
+		[]string{"match_rate", "", "", decimal.NewFromFloat(summary.MatchRate).Round(4).String()},
+	)
+
+	return rows
+}
+
+func matchedRows(matches []domain.Match) [][]string {
+	rows := [][]string{{
+		"trxID", "system_amount", "type", "transactionTime",
+		"bank_id", "unique_identifier", "bank_amount", "date",
+		"amount_diff", "strategy",
+	}}
+
+	for _, m := range matches {
+		rows = append(rows, []string{
+			m.SystemTxn.TrxID, m.SystemTxn.Amount.String(), string(m.SystemTxn.Type), m.SystemTxn.TransactionTime.Format(csvTimeFormat),
+			m.BankTxn.BankID, m.BankTxn.UniqID, m.BankTxn.Amount.String(), m.BankTxn.Date.Format(csvDateFormat),
+			m.AmmountDiff.String(), m.Strategy,
+		})
+	}
+
+	return rows
+}
+
+func unmatchedSystemRows(txns []domain.SystemTransaction) [][]string {
+	rows := [][]string{{"trxID", "amount", "type", "transactionTime"}}
+	for _, txn := range txns {
+		rows = append(rows, []string{txn.TrxID, txn.Amount.String(), string(txn.Type), txn.TransactionTime.Format(csvTimeFormat)})
+	}
+	return rows
+}
+
+// unmatchedBankRows lists the unmatched bank transactions of every bank, in bank order
+func unmatchedBankRows(txns map[string][]domain.BankTransaction) [][]string {
+	rows := [][]string{{"bank_id", "unique_identifier", "amount", "date"}}
+	for _, bankID := range slices.Sorted(maps.Keys(txns)) {
+		for _, txn := range txns[bankID] {
+			rows = append(rows, []string{bankID, txn.UniqID, txn.Amount.String(), txn.Date.Format(csvDateFormat)})
+		}
+	}
+	return rows
+}
+
+// agingRows lists the aging buckets of the unmatched system transactions, then those of every bank in bank order
+func agingRows(aging domain.Aging) [][]string {
+	rows := [][]string{{"source", "bucket", "count", "amount"}}
+
+	addBuckets := func(source string, buckets domain.AgingBuckets) {
+		for _, bucket := range buckets {
+			rows = append(rows, []string{source, bucket.Bucket, strconv.Itoa(bucket.Count), bucket.Amount.String()})
+		}
+	}
+
+	addBuckets("system", aging.System)
+	for _, bankID := range slices.Sorted(maps.Keys(aging.Bank)) {
+		addBuckets(bankID, aging.Bank[bankID])
+	}
+
+	return rows
+}
+
+// openItemRows lists the items still open at the end of the period, the system ones first
+func openItemRows(carried domain.CarriedForward) [][]string {
+	rows := [][]string{{"source", "id", "amount", "type", "date", "age_days"}}
+	for _, item := range carried.OpenSystemTxns {
+		rows = append(rows, []string{
+			"system", item.TrxID, item.Amount.String(), string(item.Type), item.TransactionTime.Format(csvTimeFormat), strconv.Itoa(item.AgeDays),
+		})
+	}
+	for _, item := range carried.OpenBankTxns {
+		rows = append(rows, []string{
+			item.BankID, item.UniqID, item.Amount.String(), "", item.Date.Format(csvDateFormat), strconv.Itoa(item.AgeDays),
+		})
+	}
+	return rows
+}
+
+// balanceRows lists the balance checks of the book and of every bank in bank order, then the lines of the
+// bank-to-book statement when there is one
+func balanceRows(balances domain.BalanceReconciliation) [][]string {
+	rows := [][]string{{"check", "key", "account", "opening", "count", "amount", "closing", "difference", "balanced"}}
+
+	addCheck := func(key string, check domain.BalanceCheck) {
+		rows = append(rows, []string{
+			"balance", key, check.Account, check.Opening.String(),
+			strconv.Itoa(check.Transactions.Count), check.Transactions.Amount.String(),
+			check.Closing.String(), check.Difference.String(), strconv.FormatBool(check.Balanced),
+		})
+	}
+
+	if balances.Book != nil {
+		addCheck("book", *balances.Book)
+	}
+	for _, bankID := range slices.Sorted(maps.Keys(balances.Bank)) {
+		addCheck(bankID, balances.Bank[bankID])
+	}
+
+	if s := balances.Statement; s != nil {
+		addLine := func(key string, count string, amount decimal.Decimal) {
+			rows = append(rows, []string{"statement", key, "", "", count, amount.String(), "", "", ""})
+		}
+		addTotal := func(key string, total domain.Total) {
+			addLine(key, strconv.Itoa(total.Count), total.Amount)
+		}
+
+		addLine("bank_balance", "", s.BankBalance)
+		addTotal("deposits_in_transit", s.DepositsInTransit)
+		addTotal("outstanding_payments", s.OutstandingPayments)
+		addLine("adjusted_bank_balance", "", s.AdjustedBankBalance)
+		addLine("book_balance", "", s.BookBalance)
+		addTotal("bank_only_items", s.BankOnlyItems)
+		addLine("adjusted_book_balance", "", s.AdjustedBookBalance)
+		addLine("difference", "", s.Difference)
+	}
+
+	return rows
+}
@@ -0,0 +1,158 @@
+package report_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+)
+
+func newTestResult() domain.ReconciliationResult {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	return domain.ReconciliationResult{
+		TotalTxnsProcessed: 5,
+		MatchedTxns: []domain.Match{{
+			SystemTxn:   domain.SystemTransaction{TrxID: "SYS-1", Amount: decimal.RequireFromString("100.05"), Type: domain.Debit, TransactionTime: day(3).Add(90 * time.Minute)},
+			BankTxn:     domain.BankTransaction{UniqID: "BNK-1", Amount: decimal.RequireFromString("-100"), Date: day(3), BankID: "bank_a"},
+			AmmountDiff: decimal.RequireFromString("0.05"),
+			Strategy:    "fuzzy",
+		}},
+		UnMatchedSystemTxns: []domain.SystemTransaction{
+			{TrxID: "SYS-2", Amount: decimal.RequireFromString("12.34"), Type: domain.Credit, TransactionTime: day(4)},
+		},
+		UnMatchedBankTxns: map[string][]domain.BankTransaction{
+			"bank_b": {{UniqID: "BNK-9", Amount: decimal.RequireFromString("1"), Date: day(9), BankID: "bank_b"}},
+			"bank_a": {{UniqID: "BNK-2", Amount: decimal.RequireFromString("-2"), Date: day(5), BankID: "bank_a"}},
+		},
+		TotalDiscrepancies: decimal.RequireFromString("0.05"),
+		Summary: domain.Summary{
+			SystemTxns:        domain.Total{Count: 2, Amount: decimal.RequireFromString("-87.71")},
+			Matched:           domain.Total{Count: 1, Amount: decimal.RequireFromString("-100")},
+			MatchedByStrategy: map[string]domain.Total{"fuzzy": {Count: 1, Amount: decimal.RequireFromString("-100")}},
+			MatchRate:         0.4,
+		},
+		Aging: domain.Aging{
+			System: domain.AgingBuckets{{Bucket: "0-2", Count: 1, Amount: decimal.RequireFromString("12.34")}},
+			Bank: map[string]domain.AgingBuckets{
+				"bank_a": {{Bucket: "0-2", Count: 0, Amount: decimal.Zero}, {Bucket: "3-7", Count: 1, Amount: decimal.RequireFromString("-2")}},
+				"bank_b": {{Bucket: "0-2", Count: 1, Amount: decimal.RequireFromString("1")}},
+			},
+		},
+	}
+}
+
+// newFullTestResult returns newTestResult with the optional sections, the carried forward items and the balances
+func newFullTestResult() domain.ReconciliationResult {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	full := newTestResult()
+	full.CarriedForward = &domain.CarriedForward{
+		Cleared: full.MatchedTxns,
+		OpenSystemTxns: []domain.AgedSystemTransaction{{
+			SystemTransaction: domain.SystemTransaction{TrxID: "SYS-0", Amount: decimal.RequireFromString("5"), Type: domain.Credit, TransactionTime: day(1)},
+			AgeDays:           30,
+		}},
+		OpenBankTxns: []domain.AgedBankTransaction{{
+			BankTransaction: domain.BankTransaction{UniqID: "BNK-0", Amount: decimal.RequireFromString("7"), Date: day(2), BankID: "bank_a"},
+			AgeDays:         29,
+		}},
+	}
+	book := domain.BalanceCheck{Account: "book", Opening: decimal.RequireFromString("10"), Closing: decimal.RequireFromString("10"), Balanced: true}
+	full.Balances = &domain.BalanceReconciliation{
+		Book:      &book,
+		Bank:      map[string]domain.BalanceCheck{"bank_a": {Account: "bank_a", Difference: decimal.RequireFromString("1")}},
+		Statement: &domain.BankToBookStatement{BankBalance: decimal.RequireFromString("100.5")},
+	}
+	return full
+}
+
+func TestCSVFormatter_FormatFiles(t *testing.T) {
+	files, err := report.NewCSVFormatter().FormatFiles(newFullTestResult())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rows := make(map[string][][]string)
+	for _, file := range files {
+		records, err := csv.NewReader(bytes.NewReader(file.Data)).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Name, err)
+		}
+		rows[file.Name] = records
+	}
+
+	tests := []struct {
+		file     string
+		row      int
+		expected []string
+	}{
+		{"matched.csv", 1, []string{"SYS-1", "100.05", "DEBIT", "2025-01-03T01:30:00", "bank_a", "BNK-1", "-100", "2025-01-03", "0.05", "fuzzy"}},
+		{"unmatched_system.csv", 0, []string{"trxID", "amount", "type", "transactionTime"}},
+		{"unmatched_system.csv", 1, []string{"SYS-2", "12.34", "CREDIT", "2025-01-04T00:00:00"}},
+		{"unmatched_bank.csv", 1, []string{"bank_a", "BNK-2", "-2", "2025-01-05"}},
+		{"unmatched_bank.csv", 2, []string{"bank_b", "BNK-9", "1", "2025-01-09"}},
+		{"summary.csv", 3, []string{"matched_by_strategy", "fuzzy", "1", "-100"}},
+		{"aging.csv", 1, []string{"system", "0-2", "1", "12.34"}},
+		{"aging.csv", 3, []string{"bank_a", "3-7", "1", "-2"}},
+		{"aging.csv", 4, []string{"bank_b", "0-2", "1", "1"}},
+		{"cleared_open_items.csv", 1, []string{"SYS-1", "100.05", "DEBIT", "2025-01-03T01:30:00", "bank_a", "BNK-1", "-100", "2025-01-03", "0.05", "fuzzy"}},
+		{"open_items.csv", 1, []string{"system", "SYS-0", "5", "CREDIT", "2025-01-01T00:00:00", "30"}},
+		{"open_items.csv", 2, []string{"bank_a", "BNK-0", "7", "", "2025-01-02", "29"}},
+		{"balances.csv", 1, []string{"balance", "book", "book", "10", "0", "0", "10", "0", "true"}},
+		{"balances.csv", 2, []string{"balance", "bank_a", "bank_a", "0", "0", "0", "0", "1", "false"}},
+		{"balances.csv", 3, []string{"statement", "bank_balance", "", "", "", "100.5", "", "", ""}},
+	}
+
+	for _, tt := range tests {
+		if len(rows[tt.file]) <= tt.row {
+			t.Errorf("Expected at least %d rows in %s, got %d", tt.row+1, tt.file, len(rows[tt.file]))
+			continue
+		}
+
+		got := rows[tt.file][tt.row]
+		if !slices.Equal(got, tt.expected) {
+			t.Errorf("Expected row %d of %s to be %v, got %v", tt.row, tt.file, tt.expected, got)
+		}
+	}
+
+	summary := rows["summary.csv"]
+	if last := summary[len(summary)-1]; last[0] != "match_rate" || last[3] != "0.4" {
+		t.Errorf("Expected the match rate last, got %v", last)
+	}
+}
+
+func TestCSVFormatter_Format(t *testing.T) {
+	output, err := report.NewCSVFormatter().Format(newTestResult())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(output), int64(len(output)))
+	if err != nil {
+		t.Fatalf("Expected a zip archive, got %v", err)
+	}
+
+	// No carried forward or balances files without those sections
+	if len(zr.File) != 5 {
+		t.Fatalf("Expected 5 files in the archive, got %d", len(zr.File))
+	}
+
+	f, err := zr.Open("matched.csv")
+	if err != nil {
+		t.Fatalf("Expected matched.csv in the archive, got %v", err)
+	}
+	defer f.Close()
+
+	data, _ := io.ReadAll(f)
+	if !bytes.HasPrefix(data, []byte("trxID,system_amount,")) {
+		t.Errorf("Expected the matched pairs header, got %q", data)
+	}
+}
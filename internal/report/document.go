@@ -0,0 +1,304 @@
+package report
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+// SchemaVersion is the version of the JSON report document, bumped on any change to its fields
+const SchemaVersion = "1.0"
+
+// JSONSchema is the JSON Schema of the report document, published as schema/report.schema.json
+//
+//go:embed schema/report.schema.json
+var JSONSchema []byte
+
+const (
+	documentDateFormat = "2006-01-02"
+	documentTimeFormat = time.RFC3339
+)
+
+// Document is the JSON report of a reconciliation. Its fields are decoupled from the domain structs, so they
+// can be refactored without breaking the consumers of the report: names are snake_case, dates ISO 8601, and
+// amounts decimal strings so they keep their precision
+type Document struct {
+	SchemaVersion              string                         `json:"schema_version"`
+	Summary                    SummaryDocument                `json:"summary"`
+	TotalTransactionsProcessed int                            `json:"total_transactions_processed"`
+	TotalDiscrepancies         string                         `json:"total_discrepancies"`
+	Matched                    []MatchDocument                `json:"matched"`
+	UnmatchedSystem            []SystemTxnDocument            `json:"unmatched_system"`
+	UnmatchedBank              map[string][]BankTxnDocument   `json:"unmatched_bank"`
+	Aging                      AgingDocument                  `json:"aging"`
+	CarriedForward             *CarriedForwardDocument        `json:"carried_forward"`
+	Balances                   *BalanceReconciliationDocument `json:"balances"`
+}
+
+type SummaryDocument struct {
+	SystemTransactions       TotalDocument            `json:"system_transactions"`
+	BankTransactions         map[string]TotalDocument `json:"bank_transactions"`
+	Matched                  TotalDocument            `json:"matched"`
+	MatchedByStrategy        map[string]TotalDocument `json:"matched_by_strategy"`
+	ClearedOpenItems         TotalDocument            `json:"cleared_open_items"`
+	UnmatchedSystem          TotalDocument            `json:"unmatched_system"`
+	UnmatchedBank            map[string]TotalDocument `json:"unmatched_bank"`
+	MatchRate                float64                  `json:"match_rate"`
+	NetUnexplainedDifference string                   `json:"net_unexplained_difference"`
+}
+
+type TotalDocument struct {
+	Count  int    `json:"count"`
+	Amount string `json:"amount"`
+}
+
+type MatchDocument struct {
+	System           SystemTxnDocument `json:"system"`
+	Bank             BankTxnDocument   `json:"bank"`
+	AmountDifference string            `json:"amount_difference"`
+	Strategy         string            `json:"strategy"`
+}
+
+type SystemTxnDocument struct {
+	ID              string `json:"id"`
+	Amount          string `json:"amount"`
+	Type            string `json:"type"`
+	TransactionTime string `json:"transaction_time"`
+}
+
+type BankTxnDocument struct {
+	BankID string `json:"bank_id"`
+	ID     string `json:"id"`
+	Amount string `json:"amount"`
+	Date   string `json:"date"`
+}
+
+type AgingDocument struct {
+	System []AgingBucketDocument            `json:"system"`
+	Bank   map[string][]AgingBucketDocument `json:"bank"`
+}
+
+type AgingBucketDocument struct {
+	Bucket string `json:"bucket"`
+	Count  int    `json:"count"`
+	Amount string `json:"amount"`
+}
+
+type CarriedForwardDocument struct {
+	Cleared    []MatchDocument         `json:"cleared"`
+	OpenSystem []AgedSystemTxnDocument `json:"open_system"`
+	OpenBank   []AgedBankTxnDocument   `json:"open_bank"`
+}
+
+type AgedSystemTxnDocument struct {
+	SystemTxnDocument
+	AgeDays int `json:"age_days"`
+}
+
+type AgedBankTxnDocument struct {
+	BankTxnDocument
+	AgeDays int `json:"age_days"`
+}
+
+type BalanceReconciliationDocument struct {
+	Book      *BalanceCheckDocument           `json:"book"`
+	Bank      map[string]BalanceCheckDocument `json:"bank"`
+	Statement *StatementDocument              `json:"statement"`
+}
+
+type BalanceCheckDocument struct {
+	Account      string        `json:"account"`
+	Opening      string        `json:"opening"`
+	Transactions TotalDocument `json:"transactions"`
+	Closing      string        `json:"closing"`
+	Difference   string        `json:"difference"`
+	Balanced     bool          `json:"balanced"`
+}
+
+type StatementDocument struct {
+	BankBalance         string        `json:"bank_balance"`
+	DepositsInTransit   TotalDocument `json:"deposits_in_transit"`
+	OutstandingPayments TotalDocument `json:"outstanding_payments"`
+	AdjustedBankBalance string        `json:"adjusted_bank_balance"`
+	BookBalance         string        `json:"book_balance"`
+	BankOnlyItems       TotalDocument `json:"bank_only_items"`
+	AdjustedBookBalance string        `json:"adjusted_book_balance"`
+	Difference          string        `json:"difference"`
+}
+
+// NewDocument converts a reconciliation result to its JSON report document. Lists and maps are never null
+func NewDocument(result domain.ReconciliationResult) Document {
+	doc := Document{
+		SchemaVersion:              SchemaVersion,
+		Summary:                    newSummaryDocument(result.Summary),
+		TotalTransactionsProcessed: result.TotalTxnsProcessed,
+		TotalDiscrepancies:         result.TotalDiscrepancies.String(),
+		Matched:                    newMatchDocuments(result.MatchedTxns),
+		UnmatchedSystem:            make([]SystemTxnDocument, 0, len(result.UnMatchedSystemTxns)),
+		UnmatchedBank:              make(map[string][]BankTxnDocument, len(result.UnMatchedBankTxns)),
+		Aging:                      newAgingDocument(result.Aging),
+	}
+
+	for _, txn := range result.UnMatchedSystemTxns {
+		doc.UnmatchedSystem = append(doc.UnmatchedSystem, newSystemTxnDocument(txn))
+	}
+
+	for bankID, txns := range result.UnMatchedBankTxns {
+		docs := make([]BankTxnDocument, 0, len(txns))
+		for _, txn := range txns {
+			docs = append(docs, newBankTxnDocument(txn))
+		}
+		doc.UnmatchedBank[bankID] = docs
+	}
+
+	if carried := result.CarriedForward; carried != nil {
+		doc.CarriedForward = &CarriedForwardDocument{
+			Cleared:    newMatchDocuments(carried.Cleared),
+			OpenSystem: make([]AgedSystemTxnDocument, 0, len(carried.OpenSystemTxns)),
+			OpenBank:   make([]AgedBankTxnDocument, 0, len(carried.OpenBankTxns)),
+		}
+		for _, item := range carried.OpenSystemTxns {
+			doc.CarriedForward.OpenSystem = append(doc.CarriedForward.OpenSystem, AgedSystemTxnDocument{newSystemTxnDocument(item.SystemTransaction), item.AgeDays})
+		}
+		for _, item := range carried.OpenBankTxns {
+			doc.CarriedForward.OpenBank = append(doc.CarriedForward.OpenBank, AgedBankTxnDocument{newBankTxnDocument(item.BankTransaction), item.AgeDays})
+		}
+	}
+
+	if balances := result.Balances; balances != nil {
+		doc.Balances = &BalanceReconciliationDocument{Bank: make(map[string]BalanceCheckDocument, len(balances.Bank))}
+		if balances.Book != nil {
+			book := newBalanceCheckDocument(*balances.Book)
+			doc.Balances.Book = &book
+		}
+		for bankID, check := range balances.Bank {
+			doc.Balances.Bank[bankID] = newBalanceCheckDocument(check)
+		}
+		if s := balances.Statement; s != nil {
+			doc.Balances.Statement = &StatementDocument{
+				BankBalance:         s.BankBalance.String(),
+				DepositsInTransit:   newTotalDocument(s.DepositsInTransit),
+				OutstandingPayments: newTotalDocument(s.OutstandingPayments),
+				AdjustedBankBalance: s.AdjustedBankBalance.String(),
+				BookBalance:         s.BookBalance.String(),
+				BankOnlyItems:       newTotalDocument(s.BankOnlyItems),
+				AdjustedBookBalance: s.AdjustedBookBalance.String(),
+				Difference:          s.Difference.String(),
+			}
+		}
+	}
+
+	return doc
+}
+
+// ReadDocument reads a JSON report document written by JSONFormatter, of any 1.x schema version
+func ReadDocument(r io.Reader) (Document, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return Document{}, fmt.Errorf("decoding JSON report: %w", err)
+	}
+
+	major, _, _ := strings.Cut(SchemaVersion, ".")
+	if version, _, _ := strings.Cut(doc.SchemaVersion, "."); version != major {
+		return Document{}, fmt.Errorf("unsupported JSON report schema version %q, expected %s.x", doc.SchemaVersion, major)
+	}
+
+	return doc, nil
+}
+
+func newSummaryDocument(summary domain.Summary) SummaryDocument {
+	return SummaryDocument{
+		SystemTransactions:       newTotalDocument(summary.SystemTxns),
+		BankTransactions:         newTotalDocuments(summary.BankTxns),
+		Matched:                  newTotalDocument(summary.Matched),
+		MatchedByStrategy:        newTotalDocuments(summary.MatchedByStrategy),
+		ClearedOpenItems:         newTotalDocument(summary.ClearedOpenItems),
+		UnmatchedSystem:          newTotalDocument(summary.UnmatchedSystem),
+		UnmatchedBank:            newTotalDocuments(summary.UnmatchedBank),
+		MatchRate:                summary.MatchRate,
+		NetUnexplainedDifference: summary.NetUnexplainedDifference.String(),
+	}
+}
+
+func newTotalDocument(total domain.Total) TotalDocument {
+	return TotalDocument{Count: total.Count, Amount: total.Amount.String()}
+}
+
+func newTotalDocuments(totals map[string]domain.Total) map[string]TotalDocument {
+	docs := make(map[string]TotalDocument, len(totals))
+	for key, total := range maps.All(totals) {
+		docs[key] = newTotalDocument(total)
+	}
+	return docs
+}
+
+func newMatchDocuments(matches []domain.Match) []MatchDocument {
+	docs := make([]MatchDocument, 0, len(matches))
+	for _, m := range matches {
+		docs = append(docs, newMatchDocument(m))
+	}
+	return docs
+}
+
+func newMatchDocument(m domain.Match) MatchDocument {
+	return MatchDocument{
+		System:           newSystemTxnDocument(m.SystemTxn),
+		Bank:             newBankTxnDocument(m.BankTxn),
+		AmountDifference: m.AmmountDiff.String(),
+		Strategy:         m.Strategy,
+	}
+}
+
+func newSystemTxnDocument(txn domain.SystemTransaction) SystemTxnDocument {
+	return SystemTxnDocument{
+		ID:              txn.TrxID,
+		Amount:          txn.Amount.String(),
+		Type:            string(txn.Type),
+		TransactionTime: txn.TransactionTime.Format(documentTimeFormat),
+	}
+}
+
+func newBankTxnDocument(txn domain.BankTransaction) BankTxnDocument {
+	return BankTxnDocument{
+		BankID: txn.BankID,
+		ID:     txn.UniqID,
+		Amount: txn.Amount.String(),
+		Date:   txn.Date.Format(documentDateFormat),
+	}
+}
+
+func newAgingDocument(aging domain.Aging) AgingDocument {
+	doc := AgingDocument{
+		System: newAgingBucketDocuments(aging.System),
+		Bank:   make(map[string][]AgingBucketDocument, len(aging.Bank)),
+	}
+	for bankID, buckets := range aging.Bank {
+		doc.Bank[bankID] = newAgingBucketDocuments(buckets)
+	}
+	return doc
+}
+
+func newAgingBucketDocuments(buckets domain.AgingBuckets) []AgingBucketDocument {
+	docs := make([]AgingBucketDocument, 0, len(buckets))
+	for _, bucket := range buckets {
+		docs = append(docs, AgingBucketDocument{Bucket: bucket.Bucket, Count: bucket.Count, Amount: bucket.Amount.String()})
+	}
+	return docs
+}
+
+func newBalanceCheckDocument(check domain.BalanceCheck) BalanceCheckDocument {
+	return BalanceCheckDocument{
+		Account:      check.Account,
+		Opening:      check.Opening.String(),
+		Transactions: newTotalDocument(check.Transactions),
+		Closing:      check.Closing.String(),
+		Difference:   check.Difference.String(),
+		Balanced:     check.Balanced,
+	}
+}
@@ -0,0 +1,130 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+)
+
+func compileSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+
+	schema, err := jsonschema.UnmarshalJSON(bytes.NewReader(report.JSONSchema))
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+	if err := compiler.AddResource("report.schema.json", schema); err != nil {
+		t.Fatalf("Failed to add schema: %v", err)
+	}
+	compiled, err := compiler.Compile("report.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to compile schema: %v", err)
+	}
+	return compiled
+}
+
+func validateDocument(t *testing.T, schema *jsonschema.Schema, data []byte) map[string]any {
+	t.Helper()
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		t.Fatalf("Expected output to match the schema, got %v", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	return fields
+}
+
+func TestJSONFormatter_MatchesSchema(t *testing.T) {
+	schema := compileSchema(t)
+	tests := []struct {
+		name   string
+		result domain.ReconciliationResult
+	}{
+		{"Empty result", domain.ReconciliationResult{}},
+		{"Typical result", newTestResult()},
+		{"Every section", newFullTestResult()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := report.NewJSONFormatter(true).Format(tt.result)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			fields := validateDocument(t, schema, data)
+			if fields["schema_version"] != report.SchemaVersion {
+				t.Errorf("Expected schema_version %s, got %v", report.SchemaVersion, fields["schema_version"])
+			}
+		})
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	doc := report.NewDocument(newTestResult())
+
+	if len(doc.Matched) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(doc.Matched))
+	}
+
+	match := doc.Matched[0]
+	if match.AmountDifference != "0.05" {
+		t.Errorf("Expected amount difference 0.05, got %s", match.AmountDifference)
+	}
+	if match.System.TransactionTime != "2025-01-03T01:30:00Z" {
+		t.Errorf("Expected transaction time 2025-01-03T01:30:00Z, got %s", match.System.TransactionTime)
+	}
+	if match.Bank.Date != "2025-01-03" {
+		t.Errorf("Expected date 2025-01-03, got %s", match.Bank.Date)
+	}
+	if doc.Summary.Matched.Amount != "-100" {
+		t.Errorf("Expected matched amount -100, got %s", doc.Summary.Matched.Amount)
+	}
+	if doc.Summary.UnmatchedBank == nil {
+		t.Errorf("Expected empty unmatched bank totals, got nil")
+	}
+	if doc.CarriedForward != nil {
+		t.Errorf("Expected no carried forward section, got %+v", doc.CarriedForward)
+	}
+}
+
+func TestJSONSchema_RejectsInvalidDocument(t *testing.T) {
+	schema := compileSchema(t)
+
+	data, err := report.NewJSONFormatter(false).Format(newTestResult())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	fields["total_discrepancies"] = 0.05
+
+	invalid, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatalf("Failed to encode document: %v", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(invalid))
+	if err != nil {
+		t.Fatalf("Failed to parse document: %v", err)
+	}
+	if err := schema.Validate(doc); err == nil {
+		t.Errorf("Expected a numeric amount to fail validation, got nil")
+	}
+}
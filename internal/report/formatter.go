@@ -1,9 +1,16 @@
 package report
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 
 	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
 )
 
 // OutputFormatter defines the interface for formatting reconciliation results
@@ -12,7 +19,73 @@ type OutputFormatter interface {
 	FileExtension() string
 }
 
-// JSONFormatter formats reconciliation results as JSON
+// MultiFileFormatter is an OutputFormatter whose output is made of several files, e.g. one per section of
+// the result. Format returns the files bundled in a zip archive
+type MultiFileFormatter interface {
+	OutputFormatter
+	FormatFiles(result domain.ReconciliationResult) ([]OutputFile, error)
+}
+
+// StreamingFormatter is an OutputFormatter that can also write a result record by record, as the service
+// produces them, so the output never has to be held in memory
+type StreamingFormatter interface {
+	OutputFormatter
+	NewRecordWriter(w io.Writer) RecordWriter
+}
+
+// RecordWriter writes the records of a result to an io.Writer as they're reported. It receives the matches and
+// unmatched transactions as a domain.MatchSink, then the rejected input rows, the aging, and the summary last
+type RecordWriter interface {
+	domain.MatchSink
+	Reject(reject fileutil.Reject) error
+	Aging(aging domain.Aging) error
+	Summary(summary domain.Summary) error
+}
+
+// OutputFile is one of the files of a multi-file output
+type OutputFile struct {
+	Name string
+	Data []byte
+}
+
+// ZipFiles bundles files in a zip archive
+func ZipFiles(files []OutputFile) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		w, err := zw.Create(file.Name)
+		if err != nil {
+			return nil, fmt.Errorf("adding %s to zip archive: %w", file.Name, err)
+		}
+		if _, err := w.Write(file.Data); err != nil {
+			return nil, fmt.Errorf("adding %s to zip archive: %w", file.Name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("closing zip archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteFiles writes files to dir, creating it when needed
+func WriteFiles(dir string, files []OutputFile) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, file.Name), file.Data, 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", file.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// JSONFormatter formats reconciliation results as the versioned JSON document, see Document and JSONSchema
 type JSONFormatter struct {
 	PrettyPrint bool
 }
@@ -25,10 +98,11 @@ func NewJSONFormatter(prettyPrint bool) *JSONFormatter {
 
 // Format implements the OutputFormatter interface for JSON
 func (f *JSONFormatter) Format(result domain.ReconciliationResult) ([]byte, error) {
+	doc := NewDocument(result)
 	if f.PrettyPrint {
-		return json.MarshalIndent(result, "", "  ")
+		return json.MarshalIndent(doc, "", "  ")
 	}
-	return json.Marshal(result)
+	return json.Marshal(doc)
 }
 
 func (f *JSONFormatter) FileExtension() string {
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+//go:embed templates/report.html.tmpl
+var templates embed.FS
+
+var htmlTemplate = template.Must(template.New("report.html.tmpl").Funcs(template.FuncMap{
+	"date":     func(t time.Time) string { return t.Format(csvDateFormat) },
+	"datetime": func(t time.Time) string { return t.Format(csvTimeFormat) },
+	"percent":  func(rate float64) string { return fmt.Sprintf("%.1f%%", rate*100) },
+}).ParseFS(templates, "templates/report.html.tmpl"))
+
+// HTMLFormatter formats reconciliation results as a single self-contained HTML page: the summary, the aging,
+// the balances, the unmatched transactions of every bank, the matched pairs and the carried forward items, in
+// tables that can be sorted and filtered without any external asset, so the file can be mailed as it is
+type HTMLFormatter struct {
+	Title string
+}
+
+func NewHTMLFormatter(title string) *HTMLFormatter {
+	if title == "" {
+		title = "Reconciliation Report"
+	}
+
+	return &HTMLFormatter{
+		Title: title,
+	}
+}
+
+// Format implements the OutputFormatter interface for HTML
+func (f *HTMLFormatter) Format(result domain.ReconciliationResult) ([]byte, error) {
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, struct {
+		Title string
+		domain.ReconciliationResult
+	}{f.Title, result})
+	if err != nil {
+		return nil, fmt.Errorf("rendering HTML report: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (f *HTMLFormatter) FileExtension() string {
+	return "html"
+}
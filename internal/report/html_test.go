@@ -0,0 +1,53 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+)
+
+func TestHTMLFormatter_Format(t *testing.T) {
+	result := newFullTestResult()
+	result.UnMatchedSystemTxns = append(result.UnMatchedSystemTxns, domain.SystemTransaction{TrxID: "<b>SYS-3</b>", Type: domain.Credit})
+
+	output, err := report.NewHTMLFormatter("").Format(result)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	html := string(output)
+
+	expected := []string{
+		"<title>Reconciliation Report</title>",
+		`<tr class="diff">`,            // SYS-1 matched with a 0.05 difference
+		`id="unmatched-bank-bank_b"`,   // A table per bank
+		`<td class="num">40.0%</td>`,   // Match rate
+		"&lt;b&gt;SYS-3&lt;/b&gt;",     // Escaped
+		`<td>2025-01-03T01:30:00</td>`, // Time of SYS-1
+		`document.querySelectorAll("table.sortable")`,
+		`<tr><td>bank_a</td><td>3-7</td><td class="num">1</td><td class="num">-2</td></tr>`,                                     // Aging
+		`<tr class="diff"><td>bank_a</td><td>bank_a</td>`,                                                                       // Unbalanced bank
+		`<td>Bank balance</td><td></td><td class="num">100.5</td>`,                                                              // Bank to book statement
+		`<tr><td>System</td><td>SYS-0</td><td>CREDIT</td><td class="num">5</td><td>2025-01-01</td><td class="num">30</td></tr>`, // Open item
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected the report to contain %q", want)
+		}
+	}
+
+	// The optional sections only show when the result has them
+	output, err = report.NewHTMLFormatter("").Format(newTestResult())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(output), "<h2>Balances</h2>") || strings.Contains(string(output), "<h2>Carried Forward</h2>") {
+		t.Error("Expected no balances or carried forward sections without them")
+	}
+
+	if strings.Contains(html, "http://") || strings.Contains(html, "https://") {
+		t.Error("Expected no external assets in the report")
+	}
+}
@@ -0,0 +1,155 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+// Types of the NDJSON records
+const (
+	RecordMatch           = "match"
+	RecordUnmatchedSystem = "unmatched_system"
+	RecordUnmatchedBank   = "unmatched_bank"
+	RecordReject          = "reject"
+	RecordAging           = "aging"
+	RecordSummary         = "summary"
+)
+
+// NDJSONFormatter formats reconciliation results as newline-delimited JSON: one record per line, its type in
+// the type field and the fields of the JSON report document in the data field. The aging and the summary records
+// come last
+type NDJSONFormatter struct {
+	// Rejects are the input rows that couldn't be parsed, they aren't part of the result
+	Rejects []fileutil.Reject
+}
+
+func NewNDJSONFormatter() *NDJSONFormatter {
+	return &NDJSONFormatter{}
+}
+
+// Format implements the OutputFormatter interface for NDJSON
+func (f *NDJSONFormatter) Format(result domain.ReconciliationResult) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteRecords(f.NewRecordWriter(&buf), result, f.Rejects); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *NDJSONFormatter) FileExtension() string {
+	return "ndjson"
+}
+
+// NewRecordWriter implements the StreamingFormatter interface for NDJSON
+func (f *NDJSONFormatter) NewRecordWriter(w io.Writer) RecordWriter {
+	return &ndjsonWriter{enc: json.NewEncoder(w)}
+}
+
+// WriteRecords writes the records of a result already in memory, in the order a streamed result gets them
+func WriteRecords(w RecordWriter, result domain.ReconciliationResult, rejects []fileutil.Reject) error {
+	for _, match := range result.MatchedTxns {
+		if err := w.Matched(match); err != nil {
+			return err
+		}
+	}
+
+	for _, txn := range result.UnMatchedSystemTxns {
+		if err := w.UnmatchedSystem(txn); err != nil {
+			return err
+		}
+	}
+
+	for _, bankID := range slices.Sorted(maps.Keys(result.UnMatchedBankTxns)) {
+		for _, txn := range result.UnMatchedBankTxns[bankID] {
+			if err := w.UnmatchedBank(txn); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, reject := range rejects {
+		if err := w.Reject(reject); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Aging(result.Aging); err != nil {
+		return err
+	}
+	return w.Summary(result.Summary)
+}
+
+// ndjsonWriter writes a line per record, it's safe for concurrent use
+type ndjsonWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// ndjsonRecord is a line of the NDJSON output, data holding the fields of the JSON report document
+type ndjsonRecord struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+type rejectDocument struct {
+	File  string   `json:"file"`
+	Line  int      `json:"line"`
+	Row   []string `json:"row"`
+	Error string   `json:"error"`
+}
+
+type summaryRecordDocument struct {
+	SchemaVersion string `json:"schema_version"`
+	SummaryDocument
+}
+
+func (w *ndjsonWriter) write(recordType string, data any) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.enc.Encode(ndjsonRecord{recordType, data}); err != nil {
+		return fmt.Errorf("writing NDJSON record: %w", err)
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) Matched(match domain.Match) error {
+	return w.write(RecordMatch, newMatchDocument(match))
+}
+
+func (w *ndjsonWriter) UnmatchedSystem(txn domain.SystemTransaction) error {
+	return w.write(RecordUnmatchedSystem, newSystemTxnDocument(txn))
+}
+
+func (w *ndjsonWriter) UnmatchedBank(txn domain.BankTransaction) error {
+	return w.write(RecordUnmatchedBank, newBankTxnDocument(txn))
+}
+
+func (w *ndjsonWriter) Reject(reject fileutil.Reject) error {
+	row := reject.Row
+	if row == nil {
+		row = []string{}
+	}
+
+	var message string
+	if reject.Err != nil {
+		message = reject.Err.Error()
+	}
+	return w.write(RecordReject, rejectDocument{reject.File, reject.Line, row, message})
+}
+
+func (w *ndjsonWriter) Aging(aging domain.Aging) error {
+	return w.write(RecordAging, newAgingDocument(aging))
+}
+
+func (w *ndjsonWriter) Summary(summary domain.Summary) error {
+	return w.write(RecordSummary, summaryRecordDocument{SchemaVersion, newSummaryDocument(summary)})
+}
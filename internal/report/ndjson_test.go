@@ -0,0 +1,126 @@
+package report_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+type testRecord struct {
+	Type string         `json:"type"`
+	Data map[string]any `json:"data"`
+}
+
+func readRecords(t *testing.T, data []byte) []testRecord {
+	t.Helper()
+
+	var records []testRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var record testRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Failed to decode line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestNDJSONFormatter_Format(t *testing.T) {
+	formatter := report.NewNDJSONFormatter()
+	formatter.Rejects = []fileutil.Reject{{File: "bank_a.csv", Line: 7, Row: []string{"BNK-X", "abc"}, Err: errors.New("invalid amount")}}
+
+	data, err := formatter.Format(newTestResult())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	records := readRecords(t, data)
+	expectedTypes := []string{"match", "unmatched_system", "unmatched_bank", "unmatched_bank", "reject", "aging", "summary"}
+	if len(records) != len(expectedTypes) {
+		t.Fatalf("Expected %d records, got %d", len(expectedTypes), len(records))
+	}
+
+	for i, expected := range expectedTypes {
+		if records[i].Type != expected {
+			t.Errorf("Expected record %d of type %s, got %s", i, expected, records[i].Type)
+		}
+	}
+
+	if diff := records[0].Data["amount_difference"]; diff != "0.05" {
+		t.Errorf("Expected amount difference 0.05, got %v", diff)
+	}
+	if txnType := records[1].Data["type"]; txnType != "CREDIT" {
+		t.Errorf("Expected transaction type CREDIT, got %v", txnType)
+	}
+	if bankID := records[2].Data["bank_id"]; bankID != "bank_a" {
+		t.Errorf("Expected the bank_a transactions first, got %v", bankID)
+	}
+	if message := records[4].Data["error"]; message != "invalid amount" {
+		t.Errorf("Expected reject error 'invalid amount', got %v", message)
+	}
+	if bank, _ := records[5].Data["bank"].(map[string]any); len(bank) != 2 {
+		t.Errorf("Expected the aging of both banks, got %v", records[5].Data)
+	}
+	if system, _ := records[5].Data["system"].([]any); len(system) != 1 || system[0].(map[string]any)["amount"] != "12.34" {
+		t.Errorf("Expected the system aging bucket, got %v", records[5].Data["system"])
+	}
+	if version := records[6].Data["schema_version"]; version != report.SchemaVersion {
+		t.Errorf("Expected schema_version %s, got %v", report.SchemaVersion, version)
+	}
+}
+
+func TestNDJSONFormatter_RejectWithoutError(t *testing.T) {
+	formatter := report.NewNDJSONFormatter()
+	formatter.Rejects = []fileutil.Reject{{File: "bank_a.csv", Line: 7}}
+
+	data, err := formatter.Format(domain.ReconciliationResult{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	records := readRecords(t, data)
+	if len(records) != 3 || records[0].Type != "reject" {
+		t.Fatalf("Expected a reject, an aging and a summary record, got %v", records)
+	}
+	if message := records[0].Data["error"]; message != "" {
+		t.Errorf("Expected an empty reject error, got %v", message)
+	}
+}
+
+func TestNDJSONFormatter_NewRecordWriter(t *testing.T) {
+	result := newTestResult()
+
+	var buf bytes.Buffer
+	w := report.NewNDJSONFormatter().NewRecordWriter(&buf)
+
+	// Every record is written as soon as it's reported
+	if err := w.Matched(result.MatchedTxns[0]); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 1 {
+		t.Errorf("Expected 1 line written, got %d", lines)
+	}
+
+	if err := w.UnmatchedSystem(result.UnMatchedSystemTxns[0]); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := w.Summary(result.Summary); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	records := readRecords(t, buf.Bytes())
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	if records[2].Type != "summary" {
+		t.Errorf("Expected the summary record last, got %s", records[2].Type)
+	}
+}
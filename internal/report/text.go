@@ -0,0 +1,185 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+const defaultTopN = 10
+
+// ANSI escape sequences of the colors of the text output
+const (
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+// TextFormatter formats reconciliation results for a terminal: the summary numbers in aligned columns, the
+// aging of the unmatched transactions, the discrepancy totals, and the largest unmatched transactions of every bank
+type TextFormatter struct {
+	Colors bool // Highlight with ANSI colors, for a terminal
+	TopN   int  // Unmatched transactions listed per bank, the largest amounts first
+}
+
+func NewTextFormatter(colors bool) *TextFormatter {
+	return &TextFormatter{
+		Colors: colors,
+		TopN:   defaultTopN,
+	}
+}
+
+// textWriter writes the lines of the text output
+type textWriter struct {
+	bytes.Buffer
+	colors bool
+}
+
+func (w *textWriter) color(color, s string) string {
+	if !w.colors || color == "" {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+func (w *textWriter) heading(title string) {
+	if w.Len() > 0 {
+		w.WriteString("\n")
+	}
+	fmt.Fprintln(w, w.color(ansiBold, title))
+}
+
+// total writes a line of the totals table, colored when color is set
+func (w *textWriter) total(label string, total domain.Total, color string) {
+	fmt.Fprintf(w, "  %-34s %s %s\n", label, w.color(color, fmt.Sprintf("%8d", total.Count)), w.color(color, fmt.Sprintf("%20s", total.Amount)))
+}
+
+// buckets writes the aging buckets of a side of the reconciliation under its label
+func (w *textWriter) buckets(label string, buckets domain.AgingBuckets) {
+	fmt.Fprintf(w, "  %s\n", label)
+	for _, bucket := range buckets {
+		w.total("  "+bucket.Bucket+" days", domain.Total{Count: bucket.Count, Amount: bucket.Amount}, "")
+	}
+}
+
+func (w *textWriter) value(label, value, color string) {
+	fmt.Fprintf(w, "  %-34s %s\n", label, w.color(color, value))
+}
+
+// Format implements the OutputFormatter interface for text
+func (f *TextFormatter) Format(result domain.ReconciliationResult) ([]byte, error) {
+	w := &textWriter{colors: f.Colors}
+	summary := result.Summary
+
+	w.heading("Summary")
+	fmt.Fprintf(w, "  %-34s %8s %20s\n", "", "Count", "Amount")
+	w.total("System transactions", summary.SystemTxns, "")
+	for _, bankID := range slices.Sorted(maps.Keys(summary.BankTxns)) {
+		w.total("Bank transactions, "+bankID, summary.BankTxns[bankID], "")
+	}
+
+	w.total("Matched pairs", summary.Matched, ansiGreen)
+	for _, strategy := range slices.Sorted(maps.Keys(summary.MatchedByStrategy)) {
+		w.total("  "+strategy, summary.MatchedByStrategy[strategy], "")
+	}
+	if summary.ClearedOpenItems.Count > 0 {
+		w.total("Cleared open items", summary.ClearedOpenItems, ansiGreen)
+	}
+
+	w.total("Unmatched system transactions", summary.UnmatchedSystem, unmatchedColor(summary.UnmatchedSystem.Count))
+	for _, bankID := range slices.Sorted(maps.Keys(summary.UnmatchedBank)) {
+		total := summary.UnmatchedBank[bankID]
+		w.total("Unmatched "+bankID, total, unmatchedColor(total.Count))
+	}
+
+	w.WriteString("\n")
+	w.value("Transactions processed", fmt.Sprintf("%d", result.TotalTxnsProcessed), "")
+	w.value("Match rate", fmt.Sprintf("%.1f%%", summary.MatchRate*100), "")
+
+	if len(result.Aging.System) > 0 || len(result.Aging.Bank) > 0 {
+		w.heading("Aging")
+		fmt.Fprintf(w, "  %-34s %8s %20s\n", "", "Count", "Amount")
+		w.buckets("Unmatched system transactions", result.Aging.System)
+		for _, bankID := range slices.Sorted(maps.Keys(result.Aging.Bank)) {
+			w.buckets("Unmatched "+bankID, result.Aging.Bank[bankID])
+		}
+	}
+
+	discrepancies := 0
+	for _, match := range result.MatchedTxns {
+		if !match.AmmountDiff.IsZero() {
+			discrepancies++
+		}
+	}
+
+	w.heading("Discrepancies")
+	w.value("Matched pairs with a difference", fmt.Sprintf("%d", discrepancies), unmatchedColor(discrepancies))
+	w.value("Total discrepancies", result.TotalDiscrepancies.String(), amountColor(result.TotalDiscrepancies, ansiYellow))
+	w.value("Net unexplained difference", summary.NetUnexplainedDifference.String(), amountColor(summary.NetUnexplainedDifference, ansiRed))
+
+	if len(result.UnMatchedSystemTxns) > 0 {
+		txns := largest(result.UnMatchedSystemTxns, f.topN(), signedSystemAmount)
+		w.heading(fmt.Sprintf("Largest unmatched system transactions (%d of %d)", len(txns), len(result.UnMatchedSystemTxns)))
+		for _, txn := range txns {
+			fmt.Fprintf(w, "  %-24s %-6s %20s  %s\n", txn.TrxID, txn.Type, txn.Amount, txn.TransactionTime.Format(csvTimeFormat))
+		}
+	}
+
+	for _, bankID := range slices.Sorted(maps.Keys(result.UnMatchedBankTxns)) {
+		all := result.UnMatchedBankTxns[bankID]
+		txns := largest(all, f.topN(), func(txn domain.BankTransaction) decimal.Decimal { return txn.Amount })
+		w.heading(fmt.Sprintf("Largest unmatched %s transactions (%d of %d)", bankID, len(txns), len(all)))
+		for _, txn := range txns {
+			fmt.Fprintf(w, "  %-24s %20s  %s\n", txn.UniqID, txn.Amount, txn.Date.Format(csvDateFormat))
+		}
+	}
+
+	return w.Bytes(), nil
+}
+
+func (f *TextFormatter) FileExtension() string {
+	return "txt"
+}
+
+func (f *TextFormatter) topN() int {
+	if f.TopN <= 0 {
+		return defaultTopN
+	}
+	return f.TopN
+}
+
+// largest returns the n transactions of txns with the largest absolute amounts, the largest first
+func largest[T any](txns []T, n int, amount func(T) decimal.Decimal) []T {
+	sorted := slices.Clone(txns)
+	slices.SortStableFunc(sorted, func(a, b T) int {
+		return amount(b).Abs().Cmp(amount(a).Abs())
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+func signedSystemAmount(txn domain.SystemTransaction) decimal.Decimal {
+	if txn.Type == domain.Debit {
+		return txn.Amount.Neg()
+	}
+	return txn.Amount
+}
+
+func unmatchedColor(count int) string {
+	if count > 0 {
+		return ansiRed
+	}
+	return ""
+}
+
+func amountColor(amount decimal.Decimal, color string) string {
+	if amount.IsZero() {
+		return ansiGreen
+	}
+	return color
+}
@@ -0,0 +1,61 @@
+package report_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/report"
+)
+
+func TestTextFormatter_Format(t *testing.T) {
+	result := newTestResult()
+	for i := range 4 {
+		result.UnMatchedBankTxns["bank_b"] = append(result.UnMatchedBankTxns["bank_b"], domain.BankTransaction{
+			UniqID: fmt.Sprintf("BNK-B%d", i), Amount: decimal.NewFromInt(int64(-10 * (i + 1))), Date: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	formatter := report.NewTextFormatter(false)
+	formatter.TopN = 3
+
+	output, err := formatter.Format(result)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := string(output)
+
+	expected := []string{
+		"  Matched pairs                             1                 -100\n",
+		"    fuzzy                                   1                 -100\n",
+		"  Match rate                         40.0%\n",
+		"  Matched pairs with a difference    1\n",
+		"Largest unmatched bank_b transactions (3 of 5)\n",
+		"  BNK-B3                                    -40  2025-01-10\n",
+		"Aging\n",
+		"  Unmatched bank_a\n    0-2 days                                0                    0\n    3-7 days                                1                   -2\n",
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected the output to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	// The smallest one is left out
+	if strings.Contains(text, "BNK-9") || strings.Contains(text, "BNK-B0") {
+		t.Errorf("Expected only the 3 largest bank_b transactions, got:\n%s", text)
+	}
+
+	if strings.Contains(text, "\x1b[") {
+		t.Errorf("Expected no colors, got:\n%s", text)
+	}
+
+	colored, _ := report.NewTextFormatter(true).Format(result)
+	if !strings.Contains(string(colored), "\x1b[31m") {
+		t.Errorf("Expected the unmatched transactions in red, got:\n%s", colored)
+	}
+}
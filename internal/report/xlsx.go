@@ -0,0 +1,400 @@
+package report
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+	"github.com/xuri/excelize/v2"
+)
+
+const maxSheetNameLength = 31
+
+// XLSXFormatter formats reconciliation results as an Excel workbook with a sheet per section: the summary, the
+// aging, the matched pairs, the unmatched system transactions, the unmatched transactions of every bank, and the
+// rejected rows. Amounts are numbers and dates are dates, headers are frozen and filterable, and the matched
+// pairs with a discrepancy are highlighted
+type XLSXFormatter struct {
+	// Rejects are the input rows that couldn't be parsed, they aren't part of the result
+	Rejects []fileutil.Reject
+}
+
+func NewXLSXFormatter() *XLSXFormatter {
+	return &XLSXFormatter{}
+}
+
+// xlsxStyles are the cell styles of the workbook
+type xlsxStyles struct {
+	header, amount, percent, date, datetime int
+}
+
+// xlsxColumn is a column of a sheet, style applies to its cells below the header
+type xlsxColumn struct {
+	header string
+	width  float64
+	style  int
+}
+
+// Format implements the OutputFormatter interface for XLSX
+func (f *XLSXFormatter) Format(result domain.ReconciliationResult) ([]byte, error) {
+	wb := excelize.NewFile()
+	defer wb.Close()
+
+	styles, err := newXLSXStyles(wb)
+	if err != nil {
+		return nil, fmt.Errorf("creating workbook styles: %w", err)
+	}
+
+	if err := wb.SetSheetName("Sheet1", "Summary"); err != nil {
+		return nil, fmt.Errorf("creating Summary sheet: %w", err)
+	}
+	if err := writeSheet(wb, "Summary", styles.header, summaryColumns(styles), xlsxSummaryRows(result, styles)); err != nil {
+		return nil, err
+	}
+
+	if err := writeSheet(wb, "Aging", styles.header, agingColumns(styles), xlsxAgingRows(result.Aging)); err != nil {
+		return nil, err
+	}
+
+	if err := writeSheet(wb, "Matched", styles.header, matchedColumns(styles), xlsxMatchedRows(result.MatchedTxns)); err != nil {
+		return nil, err
+	}
+	if err := highlightDiscrepancies(wb, len(result.MatchedTxns)); err != nil {
+		return nil, err
+	}
+
+	if err := writeSheet(wb, "Unmatched System", styles.header, unmatchedSystemColumns(styles), xlsxUnmatchedSystemRows(result.UnMatchedSystemTxns)); err != nil {
+		return nil, err
+	}
+
+	used := map[string]bool{"Summary": true, "Aging": true, "Matched": true, "Unmatched System": true, "Rejects": true}
+	for _, bankID := range slices.Sorted(maps.Keys(result.UnMatchedBankTxns)) {
+		name := sheetName("Unmatched "+bankID, used)
+		if err := writeSheet(wb, name, styles.header, unmatchedBankColumns(styles), xlsxUnmatchedBankRows(result.UnMatchedBankTxns[bankID])); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeSheet(wb, "Rejects", styles.header, rejectColumns(), xlsxRejectRows(f.Rejects)); err != nil {
+		return nil, err
+	}
+
+	buf, err := wb.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("writing workbook: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (f *XLSXFormatter) FileExtension() string {
+	return "xlsx"
+}
+
+func newXLSXStyles(wb *excelize.File) (xlsxStyles, error) {
+	var styles xlsxStyles
+	dateFormat, datetimeFormat := "yyyy-mm-dd", "yyyy-mm-dd hh:mm:ss"
+
+	for _, style := range []struct {
+		id    *int
+		style *excelize.Style
+	}{
+		{&styles.header, &excelize.Style{Font: &excelize.Font{Bold: true}, Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"F2F2F2"}}}},
+		{&styles.amount, &excelize.Style{NumFmt: 4}},   // #,##0.00
+		{&styles.percent, &excelize.Style{NumFmt: 10}}, // 0.00%
+		{&styles.date, &excelize.Style{CustomNumFmt: &dateFormat}},
+		{&styles.datetime, &excelize.Style{CustomNumFmt: &datetimeFormat}},
+	} {
+		id, err := wb.NewStyle(style.style)
+		if err != nil {
+			return xlsxStyles{}, err
+		}
+		*style.id = id
+	}
+
+	return styles, nil
+}
+
+// writeSheet writes the header and the rows of a sheet, creating it when needed, and freezes and filters the header
+func writeSheet(wb *excelize.File, name string, headerStyle int, columns []xlsxColumn, rows [][]any) error {
+	if _, err := wb.NewSheet(name); err != nil {
+		return fmt.Errorf("creating %s sheet: %w", name, err)
+	}
+
+	if err := fillSheet(wb, name, headerStyle, columns, rows); err != nil {
+		return fmt.Errorf("writing %s sheet: %w", name, err)
+	}
+
+	lastColumn, _ := excelize.ColumnNumberToName(len(columns))
+	if err := wb.AutoFilter(name, fmt.Sprintf("A1:%s%d", lastColumn, len(rows)+1), nil); err != nil {
+		return fmt.Errorf("filtering %s sheet: %w", name, err)
+	}
+
+	if err := wb.SetPanes(name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return fmt.Errorf("freezing %s header: %w", name, err)
+	}
+
+	return nil
+}
+
+// fillSheet writes the header and the rows of a sheet, and styles their cells
+func fillSheet(wb *excelize.File, name string, headerStyle int, columns []xlsxColumn, rows [][]any) error {
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column.header
+	}
+
+	if err := wb.SetSheetRow(name, "A1", &header); err != nil {
+		return err
+	}
+
+	lastColumn, _ := excelize.ColumnNumberToName(len(columns))
+	if err := wb.SetCellStyle(name, "A1", lastColumn+"1", headerStyle); err != nil {
+		return err
+	}
+
+	// Cells styled on their own are styled after their column
+	var styledCells []excelize.Cell
+	var styledRefs []string
+
+	for i, row := range rows {
+		values := make([]any, len(row))
+		for j, value := range row {
+			switch v := value.(type) {
+			case excelize.Cell:
+				ref, _ := excelize.CoordinatesToCellName(j+1, i+2)
+				styledCells, styledRefs = append(styledCells, v), append(styledRefs, ref)
+				value = v.Value
+			case decimal.Decimal:
+				value = v.InexactFloat64()
+			}
+			values[j] = value
+		}
+
+		if err := wb.SetSheetRow(name, fmt.Sprintf("A%d", i+2), &values); err != nil {
+			return err
+		}
+	}
+
+	for i, column := range columns {
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		if err := wb.SetColWidth(name, col, col, column.width); err != nil {
+			return err
+		}
+
+		if column.style != 0 && len(rows) > 0 {
+			if err := wb.SetCellStyle(name, col+"2", fmt.Sprintf("%s%d", col, len(rows)+1), column.style); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, cell := range styledCells {
+		if err := wb.SetCellStyle(name, styledRefs[i], styledRefs[i], cell.StyleID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// highlightDiscrepancies highlights the amount differences of the matched pairs that aren't zero
+func highlightDiscrepancies(wb *excelize.File, matches int) error {
+	if matches == 0 {
+		return nil
+	}
+
+	style, err := wb.NewConditionalStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "9C5700"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFEB9C"}},
+	})
+	if err != nil {
+		return fmt.Errorf("highlighting discrepancies: %w", err)
+	}
+
+	err = wb.SetConditionalFormat("Matched", fmt.Sprintf("I2:I%d", matches+1), []excelize.ConditionalFormatOptions{
+		{Type: "cell", Criteria: "!=", Format: &style, Value: "0"},
+	})
+	if err != nil {
+		return fmt.Errorf("highlighting discrepancies: %w", err)
+	}
+
+	return nil
+}
+
+// sheetName returns a valid sheet name for name not in used yet, and adds it to used
+func sheetName(name string, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	candidate := truncateRunes(name, maxSheetNameLength)
+	for i := 2; used[candidate]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		candidate = truncateRunes(name, maxSheetNameLength-len(suffix)) + suffix
+	}
+
+	used[candidate] = true
+	return candidate
+}
+
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+func summaryColumns(styles xlsxStyles) []xlsxColumn {
+	return []xlsxColumn{
+		{"Metric", 32, 0},
+		{"Key", 20, 0},
+		{"Count", 10, 0},
+		{"Amount", 18, styles.amount},
+	}
+}
+
+// xlsxSummaryRows lists the totals of the summary, keyed by bank or strategy where they're broken down
+func xlsxSummaryRows(result domain.ReconciliationResult, styles xlsxStyles) [][]any {
+	summary := result.Summary
+	var rows [][]any
+
+	addTotal := func(metric, key string, total domain.Total) {
+		rows = append(rows, []any{metric, key, total.Count, total.Amount})
+	}
+	addTotals := func(metric string, totals map[string]domain.Total) {
+		for _, key := range slices.Sorted(maps.Keys(totals)) {
+			addTotal(metric, key, totals[key])
+		}
+	}
+
+	addTotal("System transactions", "", summary.SystemTxns)
+	addTotals("Bank transactions", summary.BankTxns)
+	addTotal("Matched", "", summary.Matched)
+	addTotals("Matched by strategy", summary.MatchedByStrategy)
+	addTotal("Cleared open items", "", summary.ClearedOpenItems)
+	addTotal("Unmatched system", "", summary.UnmatchedSystem)
+	addTotals("Unmatched bank", summary.UnmatchedBank)
+
+	return append(rows,
+		[]any{"Transactions processed", "", result.TotalTxnsProcessed, nil},
+		[]any{"Total discrepancies", "", nil, result.TotalDiscrepancies},
+		[]any{"Net unexplained difference", "", nil, summary.NetUnexplainedDifference},
+		[]any{"Match rate", "", nil, excelize.Cell{StyleID: styles.percent, Value: summary.MatchRate}},
+	)
+}
+
+func agingColumns(styles xlsxStyles) []xlsxColumn {
+	return []xlsxColumn{
+		{"Source", 20, 0},
+		{"Bucket (days)", 14, 0},
+		{"Count", 10, 0},
+		{"Amount", 18, styles.amount},
+	}
+}
+
+// xlsxAgingRows lists a row per bucket of the unmatched system transactions, then of every bank in bank order
+func xlsxAgingRows(aging domain.Aging) [][]any {
+	var rows [][]any
+
+	addBuckets := func(source string, buckets domain.AgingBuckets) {
+		for _, bucket := range buckets {
+			rows = append(rows, []any{source, bucket.Bucket, bucket.Count, bucket.Amount})
+		}
+	}
+
+	addBuckets("System", aging.System)
+	for _, bankID := range slices.Sorted(maps.Keys(aging.Bank)) {
+		addBuckets(bankID, aging.Bank[bankID])
+	}
+
+	return rows
+}
+
+func matchedColumns(styles xlsxStyles) []xlsxColumn {
+	return []xlsxColumn{
+		{"Transaction ID", 20, 0},
+		{"System Amount", 16, styles.amount},
+		{"Type", 10, 0},
+		{"Transaction Time", 20, styles.datetime},
+		{"Bank", 16, 0},
+		{"Unique ID", 20, 0},
+		{"Bank Amount", 16, styles.amount},
+		{"Date", 12, styles.date},
+		{"Difference", 12, styles.amount},
+		{"Strategy", 12, 0},
+	}
+}
+
+func xlsxMatchedRows(matches []domain.Match) [][]any {
+	rows := make([][]any, 0, len(matches))
+	for _, m := range matches {
+		rows = append(rows, []any{
+			m.SystemTxn.TrxID, m.SystemTxn.Amount, string(m.SystemTxn.Type), m.SystemTxn.TransactionTime,
+			m.BankTxn.BankID, m.BankTxn.UniqID, m.BankTxn.Amount, m.BankTxn.Date,
+			m.AmmountDiff, m.Strategy,
+		})
+	}
+	return rows
+}
+
+func unmatchedSystemColumns(styles xlsxStyles) []xlsxColumn {
+	return []xlsxColumn{
+		{"Transaction ID", 20, 0},
+		{"Amount", 16, styles.amount},
+		{"Type", 10, 0},
+		{"Transaction Time", 20, styles.datetime},
+	}
+}
+
+func xlsxUnmatchedSystemRows(txns []domain.SystemTransaction) [][]any {
+	rows := make([][]any, 0, len(txns))
+	for _, txn := range txns {
+		rows = append(rows, []any{txn.TrxID, txn.Amount, string(txn.Type), txn.TransactionTime})
+	}
+	return rows
+}
+
+func unmatchedBankColumns(styles xlsxStyles) []xlsxColumn {
+	return []xlsxColumn{
+		{"Unique ID", 20, 0},
+		{"Amount", 16, styles.amount},
+		{"Date", 12, styles.date},
+	}
+}
+
+func xlsxUnmatchedBankRows(txns []domain.BankTransaction) [][]any {
+	rows := make([][]any, 0, len(txns))
+	for _, txn := range txns {
+		rows = append(rows, []any{txn.UniqID, txn.Amount, txn.Date})
+	}
+	return rows
+}
+
+func rejectColumns() []xlsxColumn {
+	return []xlsxColumn{
+		{"File", 30, 0},
+		{"Line", 8, 0},
+		{"Error", 40, 0},
+		{"Row", 60, 0},
+	}
+}
+
+func xlsxRejectRows(rejects []fileutil.Reject) [][]any {
+	rows := make([][]any, 0, len(rejects))
+	for _, reject := range rejects {
+		var message string
+		if reject.Err != nil {
+			message = reject.Err.Error()
+		}
+		rows = append(rows, []any{reject.File, reject.Line, message, strings.Join(reject.Row, ",")})
+	}
+	return rows
+}
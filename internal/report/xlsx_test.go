@@ -0,0 +1,78 @@
+package report_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/tirasundara/reconciliation-service/internal/report"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXLSXFormatter_Format(t *testing.T) {
+	formatter := report.NewXLSXFormatter()
+	formatter.Rejects = []fileutil.Reject{{File: "bank_a.csv", Line: 7, Row: []string{"BNK-X", "oops"}, Err: errors.New("invalid amount format")}}
+
+	output, err := formatter.Format(newTestResult())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wb, err := excelize.OpenReader(bytes.NewReader(output))
+	if err != nil {
+		t.Fatalf("Expected a workbook, got %v", err)
+	}
+	defer wb.Close()
+
+	expectedSheets := []string{"Summary", "Aging", "Matched", "Unmatched System", "Unmatched bank_a", "Unmatched bank_b", "Rejects"}
+	if sheets := wb.GetSheetList(); !slices.Equal(sheets, expectedSheets) {
+		t.Fatalf("Expected sheets %v, got %v", expectedSheets, sheets)
+	}
+
+	// Amounts are numbers, not text
+	for _, cell := range []struct{ sheet, cell, value string }{
+		{"Matched", "B2", "100.05"},
+		{"Matched", "I2", "0.05"},
+		{"Unmatched bank_a", "B2", "-2"},
+		{"Aging", "D4", "-2"},
+	} {
+		cellType, err := wb.GetCellType(cell.sheet, cell.cell)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		value, _ := wb.GetCellValue(cell.sheet, cell.cell, excelize.Options{RawCellValue: true})
+		if cellType == excelize.CellTypeSharedString || cellType == excelize.CellTypeInlineString || value != cell.value {
+			t.Errorf("Expected %s!%s to be the number %s, got %q of type %v", cell.sheet, cell.cell, cell.value, value, cellType)
+		}
+	}
+
+	// Dates are date serials shown as dates
+	if date, _ := wb.GetCellValue("Matched", "H2"); date != "2025-01-03" {
+		t.Errorf("Expected the bank date 2025-01-03, got %q", date)
+	}
+	if raw, _ := wb.GetCellValue("Matched", "H2", excelize.Options{RawCellValue: true}); raw != "45660" {
+		t.Errorf("Expected the date serial 45660, got %q", raw)
+	}
+
+	panes, err := wb.GetPanes("Matched")
+	if err != nil || !panes.Freeze || panes.YSplit != 1 {
+		t.Errorf("Expected the header row frozen, got %+v (%v)", panes, err)
+	}
+
+	formats, err := wb.GetConditionalFormats("Matched")
+	if err != nil || len(formats["I2:I2"]) != 1 {
+		t.Errorf("Expected the differences highlighted, got %+v (%v)", formats, err)
+	}
+
+	// A row per bucket, the system ones first
+	if rows, _ := wb.GetRows("Aging"); len(rows) != 5 || !slices.Equal(rows[3][:3], []string{"bank_a", "3-7", "1"}) {
+		t.Errorf("Expected a row per aging bucket, got %v", rows)
+	}
+
+	if reject, _ := wb.GetCellValue("Rejects", "C2"); reject != "invalid amount format" {
+		t.Errorf("Expected the reject error, got %q", reject)
+	}
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+// BookAccount is the bank_id of the balances of the book, the ledger of the system transactions
+const BookAccount = "book"
+
+var balanceHeaderFields = []string{"bank_id", "opening_balance", "closing_balance"}
+
+// CSVBalanceRepository implements the BalanceRepository interface for a CSV file holding a row of balances per
+// bank account, and one for the book. Optional account, start_date and end_date columns name the account, and the
+// period of the balances: rows of other periods are skipped, rows without dates apply to any period. The
+// balances of the accounts of a bank are summed
+type CSVBalanceRepository struct {
+	FilePath   string
+	DateFormat string
+
+	// Encoding is the character encoding of the file, detected from its first bytes by default
+	Encoding fileutil.Encoding
+
+	// RejectHandler receives the rows that couldn't be parsed, defaults to printing a warning
+	RejectHandler func(fileutil.Reject)
+}
+
+// NewCSVBalanceRepository creates a new CSVBalanceRepository
+func NewCSVBalanceRepository(filePath, dateFormat string) *CSVBalanceRepository {
+	if dateFormat == "" {
+		dateFormat = "2006-01-02" // Default format
+	}
+
+	return &CSVBalanceRepository{
+		FilePath:      filePath,
+		DateFormat:    dateFormat,
+		RejectHandler: printRejectWarning,
+	}
+}
+
+// balanceRow is a row of the balance file
+type balanceRow struct {
+	bankID    string
+	balance   domain.StatementBalance
+	startDate time.Time // Zero when the row has no period
+	endDate   time.Time
+}
+
+// GetBalances gets the balances of the period between startDate and endDate
+func (r *CSVBalanceRepository) GetBalances(ctx context.Context, startDate, endDate time.Time) (domain.Balances, error) {
+	startDay := startDate.Truncate(24 * time.Hour)
+	endDay := endDate.Truncate(24 * time.Hour)
+
+	loader := &fileutil.Loader[balanceRow]{
+		Decoder: func(header []string) (fileutil.RowDecoder[balanceRow], error) {
+			return newBalanceRowDecoder(header, r.DateFormat)
+		},
+		Filter: func(row balanceRow) bool {
+			return row.startDate.IsZero() || (row.startDate.Equal(startDay) && row.endDate.Equal(endDay))
+		},
+		Reject: r.RejectHandler,
+	}
+
+	src := fileutil.NewCSVReader(r.FilePath)
+	src.Encoding = r.Encoding
+
+	rows, err := loader.Load(ctx, src)
+	if err != nil {
+		return domain.Balances{}, fmt.Errorf("processing balances: %w", err)
+	}
+
+	// Balances are keyed by bank and account. The transactions don't name their account, so the accounts of a
+	// bank are checked together, their balances summed
+	seen := make(map[[2]string]bool)
+	byBank := make(map[string]domain.StatementBalance)
+	for _, row := range rows {
+		key := [2]string{row.bankID, row.balance.Account}
+		if seen[key] {
+			return domain.Balances{}, fmt.Errorf("processing balances: %w", duplicateBalancesError(row))
+		}
+		seen[key] = true
+
+		total, found := byBank[row.bankID]
+		if !found {
+			byBank[row.bankID] = row.balance
+			continue
+		}
+		total.Account = strings.Join([]string{total.Account, row.balance.Account}, ", ")
+		total.Opening = total.Opening.Add(row.balance.Opening)
+		total.Closing = total.Closing.Add(row.balance.Closing)
+		byBank[row.bankID] = total
+	}
+
+	balances := domain.Balances{Bank: byBank}
+	if book, found := byBank[BookAccount]; found {
+		balances.Book = &book
+		delete(byBank, BookAccount)
+	}
+
+	return balances, nil
+}
+
+// duplicateBalancesError reports a second row of balances of the same bank and account
+func duplicateBalancesError(row balanceRow) error {
+	name := "bank " + row.bankID
+	if row.bankID == BookAccount {
+		name = "the book"
+	}
+	if row.balance.Account != "" {
+		return fmt.Errorf("duplicate balances of account %s of %s", row.balance.Account, name)
+	}
+	return fmt.Errorf("duplicate balances of %s", name)
+}
+
+// newBalanceRowDecoder maps the header columns and returns a decoder turning a row into a balanceRow
+func newBalanceRowDecoder(header []string, dateFormat string) (fileutil.RowDecoder[balanceRow], error) {
+	columnMap, err := crateHeaderMap(header, balanceHeaderFields)
+	if err != nil {
+		return nil, fmt.Errorf("mapping CSV columns: %w", err)
+	}
+
+	// The optional columns, the period being both dates or none
+	for _, column := range []string{"account", "start_date", "end_date"} {
+		for i, field := range header {
+			if strings.EqualFold(column, field) {
+				columnMap[column] = i
+				break
+			}
+		}
+	}
+
+	_, hasStart := columnMap["start_date"]
+	_, hasEnd := columnMap["end_date"]
+	if hasStart != hasEnd {
+		return nil, fmt.Errorf("mapping CSV columns: start_date and end_date go together")
+	}
+
+	maxIndex := maxColumnIndex(columnMap)
+
+	return func(row []string) (balanceRow, error) {
+		if len(row) <= maxIndex {
+			return balanceRow{}, fmt.Errorf("invalid row: expected at least %d fields, got %d", maxIndex+1, len(row))
+		}
+
+		opening, err := decimal.NewFromString(row[columnMap["opening_balance"]])
+		if err != nil {
+			return balanceRow{}, fmt.Errorf("invalid opening balance format: %w", err)
+		}
+
+		closing, err := decimal.NewFromString(row[columnMap["closing_balance"]])
+		if err != nil {
+			return balanceRow{}, fmt.Errorf("invalid closing balance format: %w", err)
+		}
+
+		balance := balanceRow{
+			bankID:  row[columnMap["bank_id"]],
+			balance: domain.StatementBalance{Opening: opening, Closing: closing},
+		}
+
+		if idx, found := columnMap["account"]; found {
+			balance.balance.Account = row[idx]
+		}
+
+		if hasStart {
+			if balance.startDate, err = time.Parse(dateFormat, row[columnMap["start_date"]]); err != nil {
+				return balanceRow{}, fmt.Errorf("invalid start date format: %w", err)
+			}
+			if balance.endDate, err = time.Parse(dateFormat, row[columnMap["end_date"]]); err != nil {
+				return balanceRow{}, fmt.Errorf("invalid end date format: %w", err)
+			}
+		}
+
+		return balance, nil
+	}, nil
+}
@@ -0,0 +1,113 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/repository"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+func TestCSVBalanceRepository_GetBalances(t *testing.T) {
+	content := "bank_id,account,opening_balance,closing_balance,start_date,end_date\n" +
+		"bank_a,123-456,1000.00,1250.50,2025-01-01,2025-01-31\n" +
+		"bank_a,123-456,1250.50,900.00,2025-02-01,2025-02-28\n" +
+		"book,,980.00,1190.50,2025-01-01,2025-01-31\n" +
+		"bank_b,789,oops,10,2025-01-01,2025-01-31\n"
+
+	fp := filepath.Join(t.TempDir(), "balances.csv")
+	if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	repo := repository.NewCSVBalanceRepository(fp, "")
+	rejects := 0
+	repo.RejectHandler = func(reject fileutil.Reject) { rejects++ }
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-01")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-31")
+
+	balances, err := repo.GetBalances(context.Background(), startDate, endDate.Add(24*time.Hour-time.Second))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(balances.Bank) != 1 {
+		t.Fatalf("Expected the January balances of bank_a only, got %+v", balances.Bank)
+	}
+
+	bankA := balances.Bank["bank_a"]
+	if bankA.Account != "123-456" || !bankA.Opening.Equal(decimal.NewFromInt(1000)) || !bankA.Closing.Equal(decimal.RequireFromString("1250.50")) {
+		t.Errorf("Expected bank_a from 1000 to 1250.50, got %+v", bankA)
+	}
+
+	if balances.Book == nil || !balances.Book.Closing.Equal(decimal.RequireFromString("1190.50")) {
+		t.Errorf("Expected the book closing at 1190.50, got %+v", balances.Book)
+	}
+
+	if rejects != 1 {
+		t.Errorf("Expected 1 rejected row, got %d", rejects)
+	}
+}
+
+func TestCSVBalanceRepository_DuplicateBalances(t *testing.T) {
+	content := "bank_id,opening_balance,closing_balance\n" +
+		"bank_a,1,2\n" +
+		"bank_a,2,3\n"
+
+	fp := filepath.Join(t.TempDir(), "balances.csv")
+	if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	_, err := repository.NewCSVBalanceRepository(fp, "").GetBalances(context.Background(), time.Now(), time.Now())
+	if err == nil {
+		t.Error("Expected an error for the duplicate balances of bank_a, got nil")
+	}
+}
+
+func TestCSVBalanceRepository_SeveralAccounts(t *testing.T) {
+	content := "bank_id,account,opening_balance,closing_balance\n" +
+		"bank_a,123-456,1000.00,1250.50\n" +
+		"bank_a,789-012,500.00,400.00\n" +
+		"bank_b,345,10,20\n"
+
+	fp := filepath.Join(t.TempDir(), "balances.csv")
+	if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	balances, err := repository.NewCSVBalanceRepository(fp, "").GetBalances(context.Background(), time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The transactions don't name their account, the accounts of bank_a are checked together
+	bankA := balances.Bank["bank_a"]
+	if bankA.Account != "123-456, 789-012" {
+		t.Errorf("Expected the accounts of bank_a, got %q", bankA.Account)
+	}
+	if !bankA.Opening.Equal(decimal.NewFromInt(1500)) || !bankA.Closing.Equal(decimal.RequireFromString("1650.50")) {
+		t.Errorf("Expected bank_a from 1500 to 1650.50, got %+v", bankA)
+	}
+
+	if len(balances.Bank) != 2 {
+		t.Errorf("Expected the balances of 2 banks, got %+v", balances.Bank)
+	}
+
+	// The same account twice is still a duplicate
+	content += "bank_a,789-012,400.00,300.00\n"
+	if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	_, err = repository.NewCSVBalanceRepository(fp, "").GetBalances(context.Background(), time.Now(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "account 789-012 of bank bank_a") {
+		t.Errorf("Expected an error for the duplicate balances of account 789-012, got %v", err)
+	}
+}
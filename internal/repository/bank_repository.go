@@ -1,12 +1,10 @@
 package repository
 
 import (
-	"encoding/csv"
+	"context"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
-	"sync"
+	"iter"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -23,6 +21,20 @@ type CSVBankRepository struct {
 	DateFormat     string
 	NumWorkers     int
 	BatchSize      int
+
+	// Input, when set, is read instead of FilePath, which then only names it (e.g. os.Stdin and "-").
+	// A stream can be read only once, and isn't indexed
+	Input io.Reader
+
+	// Encoding is the character encoding of the file, detected from its first bytes by default
+	Encoding fileutil.Encoding
+
+	// IndexPath is the day index used to seek to the requested dates, defaults to the file path + ".idx".
+	// The index is only used when it exists and the file didn't change since it was built
+	IndexPath string
+
+	// RejectHandler receives the rows that couldn't be parsed, defaults to printing a warning
+	RejectHandler func(fileutil.Reject)
 }
 
 // NewCSVBankRepository creates a new CSVBankRepository
@@ -32,8 +44,7 @@ func NewCSVBankRepository(filePath string, dateFormat string) *CSVBankRepository
 	}
 
 	// Try to get bankID from its filename for now
-	bankID := filepath.Base(filePath)
-	bankID = bankID[:len(bankID)-4] // Remove .csv extension
+	bankID := fileutil.TrimExtensions(filePath) // Remove .csv and compression extensions
 
 	return &CSVBankRepository{
 		FilePath:       filePath,
@@ -41,6 +52,7 @@ func NewCSVBankRepository(filePath string, dateFormat string) *CSVBankRepository
 		DateFormat:     dateFormat,
 		NumWorkers:     4,
 		BatchSize:      1000,
+		RejectHandler:  printRejectWarning,
 	}
 }
 
@@ -48,248 +60,94 @@ func (r *CSVBankRepository) GetBankIdentifier() string {
 	return r.BankIdentifier
 }
 
-func (r *CSVBankRepository) GetTransactionsInRange(startDate, endDate time.Time) ([]domain.BankTransaction, error) {
-	reader := fileutil.NewCSVReader(r.FilePath)
-
-	// Read just the header row
-	header, err := reader.ReadHeader()
-	if err != nil {
-		return nil, fmt.Errorf("reading bank statement header: %w", err)
-	}
-
-	columnMap, err := crateHeaderMap(header, bankHeaderFields)
+func (r *CSVBankRepository) GetTransactionsInRange(ctx context.Context, startDate, endDate time.Time) ([]domain.BankTransaction, error) {
+	txns, err := r.loader(startDate, endDate).Load(ctx, r.source(startDate, endDate))
 	if err != nil {
-		return nil, fmt.Errorf("mapping CSV columns: %w", err)
-	}
-
-	var txns []domain.BankTransaction
-	var rowProcessorFn = func(row []string) error {
-		// Find the highest column index needed
-		maxIndex := -1
-		for _, idx := range columnMap {
-			if idx > maxIndex {
-				maxIndex = idx
-			}
-		}
-
-		// Skip if row doesn't have enough fields
-		if len(row) <= maxIndex {
-			return nil
-		}
-
-		txDate, err := time.Parse(r.DateFormat, row[columnMap["date"]])
-		if err != nil {
-			// Log but continue processing other rows
-			fmt.Printf("Warning: Invalid date format: %v\n", err)
-			return nil
-		}
-
-		amount, err := decimal.NewFromString(row[columnMap["amount"]])
-		if err != nil {
-			fmt.Printf("Warning: Invalid amount format: %v\n", err)
-			return nil
-		}
-
-		txn := domain.BankTransaction{
-			UniqID: row[columnMap["unique_identifier"]],
-			Amount: amount,
-			Date:   txDate,
-			BankID: r.BankIdentifier,
-		}
-
-		txns = append(txns, txn)
-		return nil
-	}
-
-	// Process data row by row
-	if err := reader.ReadAndProcessByRow(rowProcessorFn); err != nil {
 		return nil, fmt.Errorf("processing bank transactions: %w", err)
 	}
 
-	// Apply date filtering after loading all transactions
-	var filteredTxns []domain.BankTransaction
-	for _, txn := range txns {
-		txnDay := txn.Date.Truncate(24 * time.Hour)
-		startDay := startDate.Truncate(24 * time.Hour)
-		endDay := endDate.Truncate(24 * time.Hour)
-
-		if (txnDay.Equal(startDay) || txnDay.After(startDay)) &&
-			(txnDay.Equal(endDay) || txnDay.Before(endDay)) {
-			filteredTxns = append(filteredTxns, txn)
-		}
-	}
-
-	return filteredTxns, nil
+	return txns, nil
 }
 
-// GetTransactionsInRangeConcurrently reads and parse CSV rows concurrently, good for handling CSV with huge rows
-func (r *CSVBankRepository) GetTransactionsInRangeConcurrently(startDate, endDate time.Time) ([]domain.BankTransaction, error) {
-	f, err := os.Open(r.FilePath)
+// GetTransactionsInRangeConcurrently reads and parse CSV rows concurrently, good for handling CSV with huge rows.
+// Transactions are returned in the same order as they appear in the file.
+func (r *CSVBankRepository) GetTransactionsInRangeConcurrently(ctx context.Context, startDate, endDate time.Time) ([]domain.BankTransaction, error) {
+	txns, err := r.loader(startDate, endDate).LoadConcurrently(ctx, r.source(startDate, endDate))
 	if err != nil {
-		return nil, fmt.Errorf("opening csv file: %w", err)
-	}
-	defer f.Close()
-
-	reader := csv.NewReader(f)
-	header, err := reader.Read()
-	if err != nil {
-		return nil, fmt.Errorf("reading system transaction header: %w", err)
-	}
-
-	columnMap, err := crateHeaderMap(header, bankHeaderFields)
-	if err != nil {
-		return nil, fmt.Errorf("mapping CSV column: %w", err)
-	}
-
-	// Set up concurrent processing
-	jobs := make(chan [][]string, r.NumWorkers)
-	results := make(chan []domain.BankTransaction, r.NumWorkers)
-	errChan := make(chan error, r.NumWorkers)
-
-	// Start the worker pool
-	var wg sync.WaitGroup
-	startBankWorkers(r.NumWorkers, &wg, jobs, results, columnMap, r.DateFormat, r.BankIdentifier, startDate, endDate)
-
-	// Start a goroutine to close results channel when all workers are done
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// Read and distribute batches of CSV records to workers
-	go func() {
-		defer close(jobs) // Close jobs channel when done reading
-
-		err := readAndDistributeBankStatements(reader, jobs, r.BatchSize)
-		if err != nil {
-			errChan <- err
-		}
-	}()
-
-	// Collect results from workers
-	txns, err := collectBankTxnResults(results, errChan)
-	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("processing bank transactions: %w", err)
 	}
 
 	return txns, nil
 }
 
-// readAndDistributeBankStatements reads statement row from CSV then distribute them to Go workers
-func readAndDistributeBankStatements(csvReader *csv.Reader, jobs chan<- [][]string, batchSize int) error {
-	batch := make([][]string, 0, batchSize)
-
-	for {
-		record, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("reading CSV record: %w", err)
-		}
-
-		batch = append(batch, record)
-
-		// When batch is full, send it to a worker
-		if len(batch) >= batchSize {
-			jobs <- batch
-			batch = make([][]string, 0, batchSize)
-		}
-	}
+// StreamTransactionsInRange yields the statement rows dated between startDate and endDate in file order,
+// decoding batches of rows concurrently while only holding the batches in flight in memory
+func (r *CSVBankRepository) StreamTransactionsInRange(ctx context.Context, startDate, endDate time.Time) iter.Seq2[domain.BankTransaction, error] {
+	txns := r.loader(startDate, endDate).StreamConcurrently(ctx, r.source(startDate, endDate))
+	return wrapStreamError(txns, "processing bank transactions")
+}
 
-	// Send any remaining records in the last batch
-	if len(batch) > 0 {
-		jobs <- batch
-	}
+// source returns the reader for the rows dated between startDate and endDate
+func (r *CSVBankRepository) source(startDate, endDate time.Time) *fileutil.CSVReader {
+	src := csvSource(r.FilePath, r.Input, r.IndexPath, startDate, endDate)
+	src.Encoding = r.Encoding
+	return src
+}
 
-	return nil
+// BuildIndex writes the day index of the statement file, whose rows must be sorted by date
+func (r *CSVBankRepository) BuildIndex() error {
+	return buildIndex(r.FilePath, r.IndexPath, "date", r.DateFormat)
 }
 
-// startBankWorkers creates a pool of worker goroutines to process batches of CSV rows
-func startBankWorkers(numWorkers int, wg *sync.WaitGroup,
-	jobs <-chan [][]string, results chan<- []domain.BankTransaction,
-	columnMap map[string]int, dateFormat, bankID string, startDate, endDate time.Time) {
+// loader returns the CSV loader for bank statements dated between startDate and endDate
+func (r *CSVBankRepository) loader(startDate, endDate time.Time) *fileutil.Loader[domain.BankTransaction] {
+	loader := newBankLoader(r.DateFormat, r.BankIdentifier, startDate, endDate)
+	loader.Reject, loader.BatchSize, loader.Workers = r.RejectHandler, r.BatchSize, r.NumWorkers
+	return loader
+}
 
-	// Find the highest column index needed
-	maxIndex := -1
-	for _, idx := range columnMap {
-		if idx > maxIndex {
-			maxIndex = idx
-		}
+// newBankLoader returns a loader decoding the statement rows of bankID dated between startDate and endDate
+func newBankLoader(dateFormat, bankID string, startDate, endDate time.Time) *fileutil.Loader[domain.BankTransaction] {
+	return &fileutil.Loader[domain.BankTransaction]{
+		Decoder: func(header []string) (fileutil.RowDecoder[domain.BankTransaction], error) {
+			return newBankRowDecoder(header, dateFormat, bankID)
+		},
+		Filter: func(txn domain.BankTransaction) bool {
+			return inDateRange(txn.Date, startDate, endDate)
+		},
 	}
+}
 
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-
-			for batch := range jobs {
-				batchResults := make([]domain.BankTransaction, 0, len(batch))
-
-				for _, row := range batch {
-
-					// Skip if row doesn't have enough fields
-					if len(row) <= maxIndex {
-						fmt.Printf("Warning: Invalid row: %v\n", row)
-						continue // Resilient. We try to process as much row as possible
-					}
-
-					txDate, err := time.Parse(dateFormat, row[columnMap["date"]])
-					if err != nil {
-						// Log but continue processing other rows
-						fmt.Printf("Warning: Invalid date format: %v\n", err)
-						continue
-					}
-
-					amount, err := decimal.NewFromString(row[columnMap["amount"]])
-					if err != nil {
-						fmt.Printf("Warning: Invalid amount format: %v\n", err)
-						continue
-					}
-
-					txn := domain.BankTransaction{
-						UniqID: row[columnMap["unique_identifier"]],
-						Amount: amount,
-						Date:   txDate,
-						BankID: bankID,
-					}
-
-					batchResults = append(batchResults, txn)
-				}
-
-				// Send the batch results if any transactions were found
-				if len(batchResults) > 0 {
-					results <- batchResults
-				}
-			}
-		}()
+// newBankRowDecoder maps the header columns and returns a decoder turning a row into a BankTransaction
+func newBankRowDecoder(header []string, dateFormat, bankID string) (fileutil.RowDecoder[domain.BankTransaction], error) {
+	columnMap, err := crateHeaderMap(header, bankHeaderFields)
+	if err != nil {
+		return nil, fmt.Errorf("mapping CSV columns: %w", err)
 	}
 
-}
-
-// collectBankTxnResults gathers processed bank transactions from all workers
-func collectBankTxnResults(results <-chan []domain.BankTransaction, errChan <-chan error) ([]domain.BankTransaction, error) {
-	var txns []domain.BankTransaction
+	// Find the highest column index needed
+	maxIndex := maxColumnIndex(columnMap)
 
-	for batch := range results {
-		// Check for errors (non-blocking)
-		select {
-		case err := <-errChan:
-			return nil, err
-		default:
-			// Continue if no errors
+	return func(row []string) (domain.BankTransaction, error) {
+		if len(row) <= maxIndex {
+			return domain.BankTransaction{}, fmt.Errorf("invalid row: expected at least %d fields, got %d", maxIndex+1, len(row))
 		}
 
-		txns = append(txns, batch...)
-	}
+		txDate, err := time.Parse(dateFormat, row[columnMap["date"]])
+		if err != nil {
+			return domain.BankTransaction{}, fmt.Errorf("invalid date format: %w", err)
+		}
 
-	// Final error check after all results are collected
-	select {
-	case err := <-errChan:
-		return nil, err
-	default:
-		// No errors
-	}
+		amount, err := decimal.NewFromString(row[columnMap["amount"]])
+		if err != nil {
+			return domain.BankTransaction{}, fmt.Errorf("invalid amount format: %w", err)
+		}
 
-	return txns, nil
+		return domain.BankTransaction{
+			UniqID: row[columnMap["unique_identifier"]],
+			Amount: amount,
+			Date:   txDate,
+			BankID: bankID,
+		}, nil
+	}, nil
 }
@@ -1,11 +1,20 @@
 package repository_test
 
 import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/tirasundara/reconciliation-service/internal/repository"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
 )
 
 func TestCSVBankRepository_GetTransactionsInRange(t *testing.T) {
@@ -15,7 +24,7 @@ func TestCSVBankRepository_GetTransactionsInRange(t *testing.T) {
 	endDate, _ := time.Parse("2006-01-02", "2025-01-18")
 
 	// Should return transactions from Jan 16-18 (3 transactions)
-	transactions, err := repo.GetTransactionsInRange(startDate, endDate)
+	transactions, err := repo.GetTransactionsInRange(context.Background(), startDate, endDate)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -45,7 +54,7 @@ func TestCSVBankRepository_GetTransactionsInRange(t *testing.T) {
 	startDate, _ = time.Parse("2006-01-02", "2023-02-01")
 	endDate, _ = time.Parse("2006-01-02", "2023-02-28")
 
-	transactions, err = repo.GetTransactionsInRange(startDate, endDate)
+	transactions, err = repo.GetTransactionsInRange(context.Background(), startDate, endDate)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -55,3 +64,218 @@ func TestCSVBankRepository_GetTransactionsInRange(t *testing.T) {
 		t.Errorf("Expected 0 transactions for out-of-range dates, got %d", len(transactions))
 	}
 }
+
+func TestCSVBankRepository_CompressedFile(t *testing.T) {
+	repo := repository.NewCSVBankRepository("../../test/testdata/compressed/bank_statements.csv.bz2", "")
+
+	if repo.GetBankIdentifier() != "bank_statements" {
+		t.Errorf("Expected bank identifier to be bank_statements, got %s", repo.GetBankIdentifier())
+	}
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-16")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-18")
+
+	transactions, err := repo.GetTransactionsInRangeConcurrently(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(transactions) != 3 {
+		t.Errorf("Expected 3 transactions, got %d", len(transactions))
+	}
+
+	// Compressed files can't be indexed
+	if err := repo.BuildIndex(); !errors.Is(err, fileutil.ErrCompressed) {
+		t.Errorf("Expected ErrCompressed, got %v", err)
+	}
+}
+
+func TestCSVBankRepository_ExcelExportWithBOM(t *testing.T) {
+	// Excel prefixes UTF-8 exports with a byte order mark, right before the first column name
+	fp := writeTestCSV(t, "bank_excel.csv", []string{
+		"\ufeffunique_identifier,amount,date",
+		"BNK-1,100.00,2025-01-15",
+		"BNK-2,-50.00,2025-01-16",
+	})
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-15")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-16")
+
+	transactions, err := repository.NewCSVBankRepository(fp, "").GetTransactionsInRange(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(transactions) != 2 || transactions[0].UniqID != "BNK-1" {
+		t.Errorf("Expected 2 transactions starting with BNK-1, got %v", transactions)
+	}
+}
+
+func TestCSVBankRepository_CancelledContext(t *testing.T) {
+	repo := repository.NewCSVBankRepository("../../test/testdata/bank_statements.csv", "")
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-01")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-31")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repo.GetTransactionsInRange(ctx, startDate, endDate); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from GetTransactionsInRange, got %v", err)
+	}
+
+	if _, err := repo.GetTransactionsInRangeConcurrently(ctx, startDate, endDate); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from GetTransactionsInRangeConcurrently, got %v", err)
+	}
+}
+
+func TestCSVBankRepository_ConcurrentPreservesFileOrder(t *testing.T) {
+	lines := []string{"unique_identifier,amount,date"}
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("BNK-%05d,%d.50,2025-01-%02d", i, i, i%28+1))
+	}
+	fp := writeTestCSV(t, "bank_order.csv", lines)
+
+	repo := repository.NewCSVBankRepository(fp, "")
+	repo.BatchSize = 7
+	repo.NumWorkers = 8
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-01")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-31")
+
+	sequential, err := repo.GetTransactionsInRange(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	concurrent, err := repo.GetTransactionsInRangeConcurrently(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(concurrent) != len(sequential) {
+		t.Fatalf("Expected %d transactions, got %d", len(sequential), len(concurrent))
+	}
+
+	for i := range sequential {
+		if concurrent[i].UniqID != sequential[i].UniqID {
+			t.Fatalf("Expected transaction %d to be %s, got %s", i, sequential[i].UniqID, concurrent[i].UniqID)
+		}
+	}
+}
+
+func TestCSVBankRepository_ConcurrentReadErrorMidFile(t *testing.T) {
+	lines := []string{"unique_identifier,amount,date"}
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("BNK-%05d,%d.50,2025-01-15", i, i))
+		if i == 1000 {
+			lines = append(lines, "BNK-BROKEN,1.00") // wrong number of fields
+		}
+	}
+	fp := writeTestCSV(t, "bank_broken.csv", lines)
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-01")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-31")
+
+	baseline := runtime.NumGoroutine()
+
+	for _, workers := range []int{1, 2, 8} {
+		repo := repository.NewCSVBankRepository(fp, "")
+		repo.BatchSize = 3
+		repo.NumWorkers = workers
+
+		txns, err := repo.GetTransactionsInRangeConcurrently(context.Background(), startDate, endDate)
+		if !errors.Is(err, csv.ErrFieldCount) {
+			t.Errorf("Expected csv.ErrFieldCount with %d workers, got %v", workers, err)
+		}
+		if txns != nil {
+			t.Errorf("Expected no transactions on error with %d workers, got %d", workers, len(txns))
+		}
+	}
+
+	waitForGoroutines(t, baseline)
+}
+
+// writeTestCSV writes lines into a CSV file inside a temporary directory and returns its path
+func writeTestCSV(t *testing.T, name string, lines []string) string {
+	t.Helper()
+
+	fp := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fp, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test CSV: %v", err)
+	}
+
+	return fp
+}
+
+// waitForGoroutines fails the test if goroutines started by the test are still running shortly after it finished
+func waitForGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected goroutines to return to %d, still %d running", baseline, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCSVBankRepository_DayIndex(t *testing.T) {
+	lines := []string{"unique_identifier,amount,date"}
+	for day := 1; day <= 28; day++ {
+		amount := "not-a-number" // only rows inside the requested range are valid
+		if day >= 10 && day <= 12 {
+			amount = fmt.Sprintf("%d.00", day)
+		}
+		lines = append(lines, fmt.Sprintf("BNK-%02d-A,%s,2025-01-%02d", day, amount, day))
+		lines = append(lines, fmt.Sprintf("BNK-%02d-B,%s,2025-01-%02d", day, amount, day))
+	}
+	fp := writeTestCSV(t, "bank_sorted.csv", lines)
+
+	repo := repository.NewCSVBankRepository(fp, "")
+	if err := repo.BuildIndex(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rejects := 0
+	repo.RejectHandler = func(fileutil.Reject) { rejects++ }
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-10")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-12")
+
+	sequential, err := repo.GetTransactionsInRange(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	concurrent, err := repo.GetTransactionsInRangeConcurrently(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(sequential) != 6 || len(concurrent) != 6 {
+		t.Errorf("Expected 6 transactions in both modes, got %d and %d", len(sequential), len(concurrent))
+	}
+
+	// The invalid rows outside the range are never read thanks to the index
+	if rejects != 0 {
+		t.Errorf("Expected no rejected rows when seeking with the index, got %d", rejects)
+	}
+
+	// Without the index the whole file is scanned, and filtered while streaming
+	repo.IndexPath = filepath.Join(t.TempDir(), "missing.idx")
+
+	txns, err := repo.GetTransactionsInRange(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(txns) != 6 {
+		t.Errorf("Expected 6 transactions, got %d", len(txns))
+	}
+
+	if rejects != 50 {
+		t.Errorf("Expected the 50 invalid rows to be rejected without the index, got %d", rejects)
+	}
+}
@@ -2,7 +2,12 @@ package repository
 
 import (
 	"fmt"
+	"iter"
+	"os"
 	"strings"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
 )
 
 // createHeaderMap creates a map of column names to their indices
@@ -26,3 +31,42 @@ func crateHeaderMap(header []string, expectedHeader []string) (map[string]int, e
 
 	return columnMap, nil
 }
+
+// maxColumnIndex returns the highest column index of the column map, a row must be longer than that
+func maxColumnIndex(columnMap map[string]int) int {
+	maxIndex := -1
+	for _, idx := range columnMap {
+		if idx > maxIndex {
+			maxIndex = idx
+		}
+	}
+	return maxIndex
+}
+
+// inDateRange reports whether t falls on a day between startDate and endDate, both inclusive
+func inDateRange(t, startDate, endDate time.Time) bool {
+	txnDay := t.Truncate(24 * time.Hour)
+	startDay := startDate.Truncate(24 * time.Hour)
+	endDay := endDate.Truncate(24 * time.Hour)
+
+	return !txnDay.Before(startDay) && !txnDay.After(endDay)
+}
+
+// printRejectWarning logs a row that couldn't be parsed, processing continues with the next rows
+func printRejectWarning(reject fileutil.Reject) {
+	fmt.Fprintf(os.Stderr, "Warning: %s line %d: %v\n", reject.File, reject.Line, reject.Err)
+}
+
+// wrapStreamError adds context to the error yielded by a transaction stream, like the slice-returning methods do
+func wrapStreamError[T any](seq iter.Seq2[T, error], message string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for txn, err := range seq {
+			if err != nil {
+				err = fmt.Errorf("%s: %w", message, err)
+			}
+			if !yield(txn, err) {
+				return
+			}
+		}
+	}
+}
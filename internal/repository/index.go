@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+// csvSource returns the reader for the rows of a CSV file dated between startDate and endDate.
+// When a fresh day index of the file exists only the indexed byte range is read, otherwise the whole file is scanned.
+// Compressed files and streams are always scanned.
+func csvSource(filePath string, input io.Reader, indexPath string, startDate, endDate time.Time) *fileutil.CSVReader {
+	if input != nil {
+		return fileutil.NewStreamCSVReader(filePath, input)
+	}
+
+	reader := fileutil.NewCSVReader(filePath)
+
+	if compression, err := fileutil.DetectCompression(filePath); err != nil || compression != fileutil.Uncompressed {
+		return reader // A missing file is reported when it's read
+	}
+
+	if indexPath == "" {
+		indexPath = fileutil.IndexPath(filePath)
+	}
+
+	idx, err := fileutil.ReadDayIndex(indexPath)
+	if err != nil {
+		// The index is optional, only a broken one is worth a warning
+		if !errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring day index %s: %v\n", indexPath, err)
+		}
+		return reader
+	}
+
+	if !idx.IsFresh(filePath) {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring stale day index %s, rebuild it with --build-index\n", indexPath)
+		return reader
+	}
+
+	return reader.WithRange(idx.Range(startDate, endDate))
+}
+
+// buildIndex indexes a CSV file sorted by dateColumn and writes the index next to it, or to indexPath if set
+func buildIndex(filePath, indexPath, dateColumn, dateFormat string) error {
+	if indexPath == "" {
+		indexPath = fileutil.IndexPath(filePath)
+	}
+
+	idx, err := fileutil.BuildDayIndex(filePath, dateColumn, dateFormat)
+	if err != nil {
+		return fmt.Errorf("indexing %s: %w", filePath, err)
+	}
+
+	return idx.WriteFile(indexPath)
+}
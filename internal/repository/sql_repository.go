@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+// SQLSystemRepository implements the SystemTransactionRepository interface for a database/sql database.
+//
+// Query selects the transactions of a period and takes two parameters, the start of the first day and the
+// start of the day after the last one, e.g.
+//
+//	SELECT id, amount, kind, booked_at FROM ledger WHERE booked_at >= ? AND booked_at < ? ORDER BY booked_at
+//
+// Its columns are read like the ones of a CSV export: times are either time values or text in DateFormat
+type SQLSystemRepository struct {
+	DB         *sql.DB
+	Query      string
+	DateFormat string
+
+	// Columns maps the transaction fields, trxID, amount, type and transactionTime, to the query columns
+	// named differently, e.g. {"trxID": "id"}. Unmapped fields are read from the columns of the same name
+	Columns map[string]string
+
+	// ArgLayout, when set, passes the query parameters as text in that layout rather than as time values,
+	// for databases storing times as text, like SQLite
+	ArgLayout string
+
+	// RejectHandler receives the rows that couldn't be parsed, defaults to printing a warning
+	RejectHandler func(fileutil.Reject)
+}
+
+// NewSQLSystemRepository creates a new SQLSystemRepository running query on db
+func NewSQLSystemRepository(db *sql.DB, query, dateFormat string) *SQLSystemRepository {
+	if dateFormat == "" {
+		dateFormat = "2006-01-02T15:04:05"
+	}
+
+	return &SQLSystemRepository{
+		DB:            db,
+		Query:         query,
+		DateFormat:    dateFormat,
+		RejectHandler: printRejectWarning,
+	}
+}
+
+// Names of the database/sql drivers of the databases SQLDriver tells apart
+const (
+	SQLiteDriver   = "sqlite"
+	PostgresDriver = "pgx"
+)
+
+// SQLDriver returns the driver opening dsn: PostgresDriver for a postgres:// or postgresql:// URL, SQLiteDriver
+// for anything else, a file path or a file: URI. The query parameters are ? for SQLite, $1 and $2 for PostgreSQL
+func SQLDriver(dsn string) string {
+	scheme, _, found := strings.Cut(dsn, "://")
+	if found && (strings.EqualFold(scheme, "postgres") || strings.EqualFold(scheme, "postgresql")) {
+		return PostgresDriver
+	}
+	return SQLiteDriver
+}
+
+func (r *SQLSystemRepository) GetTransactionsInRange(ctx context.Context, startDate, endDate time.Time) ([]domain.SystemTransaction, error) {
+	var txns []domain.SystemTransaction
+	for txn, err := range r.StreamTransactionsInRange(ctx, startDate, endDate) {
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+
+	return txns, nil
+}
+
+// GetTransactionsInRangeConcurrently is GetTransactionsInRange: the rows come from a single result set,
+// and the database does the heavy lifting
+func (r *SQLSystemRepository) GetTransactionsInRangeConcurrently(ctx context.Context, startDate, endDate time.Time) ([]domain.SystemTransaction, error) {
+	return r.GetTransactionsInRange(ctx, startDate, endDate)
+}
+
+// StreamTransactionsInRange yields the transactions made between startDate and endDate in query order,
+// scanning one row at a time
+func (r *SQLSystemRepository) StreamTransactionsInRange(ctx context.Context, startDate, endDate time.Time) iter.Seq2[domain.SystemTransaction, error] {
+	txns := func(yield func(domain.SystemTransaction, error) bool) {
+		rows, err := r.DB.QueryContext(ctx, r.Query, r.queryArgs(startDate, endDate)...)
+		if err != nil {
+			yield(domain.SystemTransaction{}, fmt.Errorf("running query: %w", err))
+			return
+		}
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		if err != nil {
+			yield(domain.SystemTransaction{}, fmt.Errorf("reading query columns: %w", err))
+			return
+		}
+
+		decode, err := newSystemRowDecoder(r.header(columns), r.DateFormat)
+		if err != nil {
+			yield(domain.SystemTransaction{}, err)
+			return
+		}
+
+		values := make([]any, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		for line := 1; rows.Next(); line++ {
+			if err := rows.Scan(dest...); err != nil {
+				yield(domain.SystemTransaction{}, fmt.Errorf("scanning row %d: %w", line, err))
+				return
+			}
+
+			row := r.formatRow(values)
+			txn, err := decode(row)
+			if err != nil {
+				if r.RejectHandler != nil {
+					r.RejectHandler(fileutil.Reject{File: "query", Line: line, Row: row, Err: err})
+				}
+				continue
+			}
+
+			if !inDateRange(txn.TransactionTime, startDate, endDate) {
+				continue
+			}
+
+			if !yield(txn, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(domain.SystemTransaction{}, fmt.Errorf("reading query rows: %w", err))
+		}
+	}
+
+	return wrapStreamError(txns, "reading and processing system transaction")
+}
+
+// queryArgs returns the query parameters, the start of the first day and of the day after the last one
+func (r *SQLSystemRepository) queryArgs(startDate, endDate time.Time) []any {
+	from := startDate.Truncate(24 * time.Hour)
+	to := endDate.Truncate(24*time.Hour).AddDate(0, 0, 1)
+
+	if r.ArgLayout != "" {
+		return []any{from.Format(r.ArgLayout), to.Format(r.ArgLayout)}
+	}
+	return []any{from, to}
+}
+
+// header renames the query columns mapped to a transaction field after that field
+func (r *SQLSystemRepository) header(columns []string) []string {
+	header := make([]string, len(columns))
+	copy(header, columns)
+
+	for field, column := range r.Columns {
+		for i, name := range columns {
+			if name == column {
+				header[i] = field
+			}
+		}
+	}
+
+	return header
+}
+
+// formatRow turns the scanned values into the fields of a CSV row
+func (r *SQLSystemRepository) formatRow(values []any) []string {
+	row := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+			row[i] = ""
+		case []byte:
+			row[i] = string(v)
+		case string:
+			row[i] = v
+		case time.Time:
+			row[i] = v.Format(r.DateFormat)
+		case float64:
+			row[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			row[i] = fmt.Sprint(v)
+		}
+	}
+	return row
+}
@@ -1,6 +1,13 @@
 package repository_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
 	"testing"
 	"time"
 
@@ -16,7 +23,7 @@ func TestCSVSystemRepository_GetTransactionsInRange(t *testing.T) {
 	endDate, _ := time.Parse("2006-01-02", "2025-01-18")
 
 	// Should return transactions from Jan 16-18 (3 transactions)
-	transactions, err := repo.GetTransactionsInRange(startDate, endDate)
+	transactions, err := repo.GetTransactionsInRange(context.Background(), startDate, endDate)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -42,7 +49,7 @@ func TestCSVSystemRepository_GetTransactionsInRange(t *testing.T) {
 	startDate, _ = time.Parse("2006-01-02", "2025-02-01")
 	endDate, _ = time.Parse("2006-01-02", "2025-02-28")
 
-	transactions, err = repo.GetTransactionsInRange(startDate, endDate)
+	transactions, err = repo.GetTransactionsInRange(context.Background(), startDate, endDate)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -52,3 +59,116 @@ func TestCSVSystemRepository_GetTransactionsInRange(t *testing.T) {
 		t.Errorf("Expected 0 transactions for out-of-range dates, got %d", len(transactions))
 	}
 }
+
+func TestCSVSystemRepository_Input(t *testing.T) {
+	content, err := os.ReadFile("../../test/testdata/system_transactions.csv")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+
+	repo := repository.NewCSVSystemRepository("-", "")
+	repo.Input = bytes.NewReader(content)
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-01")
+	endDate, _ := time.Parse("2006-01-02", "2025-12-31")
+
+	transactions, err := repo.GetTransactionsInRangeConcurrently(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected, err := repository.NewCSVSystemRepository("../../test/testdata/system_transactions.csv", "").
+		GetTransactionsInRange(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(expected) == 0 || len(transactions) != len(expected) {
+		t.Errorf("Expected %d transactions from the stream, got %d", len(expected), len(transactions))
+	}
+}
+
+func TestCSVSystemRepository_CancelledContext(t *testing.T) {
+	repo := repository.NewCSVSystemRepository("../../test/testdata/system_transactions.csv", "")
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-01")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-31")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repo.GetTransactionsInRange(ctx, startDate, endDate); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from GetTransactionsInRange, got %v", err)
+	}
+
+	if _, err := repo.GetTransactionsInRangeConcurrently(ctx, startDate, endDate); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from GetTransactionsInRangeConcurrently, got %v", err)
+	}
+}
+
+func TestCSVSystemRepository_ConcurrentPreservesFileOrder(t *testing.T) {
+	lines := []string{"trxID,amount,type,transactionTime"}
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("SYS-%05d,%d.00,CREDIT,2025-01-%02dT10:00:00", i, i, i%28+1))
+	}
+	fp := writeTestCSV(t, "system_order.csv", lines)
+
+	repo := repository.NewCSVSystemRepository(fp, "")
+	repo.BatchSize = 7
+	repo.NumWorkers = 8
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-05")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-20")
+
+	sequential, err := repo.GetTransactionsInRange(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	concurrent, err := repo.GetTransactionsInRangeConcurrently(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(concurrent) != len(sequential) {
+		t.Fatalf("Expected %d transactions, got %d", len(sequential), len(concurrent))
+	}
+
+	for i := range sequential {
+		if concurrent[i].TrxID != sequential[i].TrxID {
+			t.Fatalf("Expected transaction %d to be %s, got %s", i, sequential[i].TrxID, concurrent[i].TrxID)
+		}
+	}
+}
+
+func TestCSVSystemRepository_ConcurrentReadErrorMidFile(t *testing.T) {
+	lines := []string{"trxID,amount,type,transactionTime"}
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("SYS-%05d,%d.00,DEBIT,2025-01-15T10:00:00", i, i))
+		if i == 1500 {
+			lines = append(lines, `SYS-BROKEN,1"00,DEBIT,2025-01-15T10:00:00`) // bare quote
+		}
+	}
+	fp := writeTestCSV(t, "system_broken.csv", lines)
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-01")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-31")
+
+	baseline := runtime.NumGoroutine()
+
+	for _, workers := range []int{1, 2, 8} {
+		repo := repository.NewCSVSystemRepository(fp, "")
+		repo.BatchSize = 3
+		repo.NumWorkers = workers
+
+		txns, err := repo.GetTransactionsInRangeConcurrently(context.Background(), startDate, endDate)
+		if !errors.Is(err, csv.ErrBareQuote) {
+			t.Errorf("Expected csv.ErrBareQuote with %d workers, got %v", workers, err)
+		}
+		if txns != nil {
+			t.Errorf("Expected no transactions on error with %d workers, got %d", workers, len(txns))
+		}
+	}
+
+	waitForGoroutines(t, baseline)
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+// XLSXBankRepository implements the BankTransactionRepository interface for Excel workbooks.
+// The sheet has the same columns as a CSV statement, and dates can be Excel dates or text in DateFormat.
+type XLSXBankRepository struct {
+	FilePath       string
+	Sheet          string // Worksheet holding the statement, defaults to the first one
+	HeaderRow      int    // Row number of the header, defaults to 1
+	BankIdentifier string
+	DateFormat     string
+	NumWorkers     int
+	BatchSize      int
+
+	// RejectHandler receives the rows that couldn't be parsed, defaults to printing a warning
+	RejectHandler func(fileutil.Reject)
+}
+
+// NewXLSXBankRepository creates a new XLSXBankRepository reading the first sheet of the workbook
+func NewXLSXBankRepository(filePath, dateFormat string) *XLSXBankRepository {
+	if dateFormat == "" {
+		dateFormat = "2006-01-02" // Default format
+	}
+
+	return &XLSXBankRepository{
+		FilePath:       filePath,
+		HeaderRow:      1,
+		BankIdentifier: fileutil.TrimExtensions(filePath),
+		DateFormat:     dateFormat,
+		NumWorkers:     4,
+		BatchSize:      1000,
+		RejectHandler:  printRejectWarning,
+	}
+}
+
+func (r *XLSXBankRepository) GetBankIdentifier() string {
+	return r.BankIdentifier
+}
+
+func (r *XLSXBankRepository) GetTransactionsInRange(ctx context.Context, startDate, endDate time.Time) ([]domain.BankTransaction, error) {
+	txns, err := r.loader(startDate, endDate).Load(ctx, r.source())
+	if err != nil {
+		return nil, fmt.Errorf("processing bank transactions: %w", err)
+	}
+
+	return txns, nil
+}
+
+// GetTransactionsInRangeConcurrently reads the sheet on one goroutine and parses its rows concurrently.
+// Transactions are returned in the same order as they appear in the sheet.
+func (r *XLSXBankRepository) GetTransactionsInRangeConcurrently(ctx context.Context, startDate, endDate time.Time) ([]domain.BankTransaction, error) {
+	txns, err := r.loader(startDate, endDate).LoadConcurrently(ctx, r.source())
+	if err != nil {
+		return nil, fmt.Errorf("processing bank transactions: %w", err)
+	}
+
+	return txns, nil
+}
+
+// StreamTransactionsInRange yields the statement rows dated between startDate and endDate in sheet order
+func (r *XLSXBankRepository) StreamTransactionsInRange(ctx context.Context, startDate, endDate time.Time) iter.Seq2[domain.BankTransaction, error] {
+	txns := r.loader(startDate, endDate).StreamConcurrently(ctx, r.source())
+	return wrapStreamError(txns, "processing bank transactions")
+}
+
+func (r *XLSXBankRepository) source() *fileutil.XLSXReader {
+	return &fileutil.XLSXReader{
+		FilePath:    r.FilePath,
+		Sheet:       r.Sheet,
+		HeaderRow:   r.HeaderRow,
+		DateColumns: []string{"date"},
+		DateLayout:  r.DateFormat,
+	}
+}
+
+func (r *XLSXBankRepository) loader(startDate, endDate time.Time) *fileutil.Loader[domain.BankTransaction] {
+	loader := newBankLoader(r.DateFormat, r.BankIdentifier, startDate, endDate)
+	loader.Reject, loader.BatchSize, loader.Workers = r.RejectHandler, r.BatchSize, r.NumWorkers
+	return loader
+}
+
+// XLSXSystemRepository implements the SystemTransactionRepository interface for Excel workbooks.
+// The sheet has the same columns as a CSV export, and transaction times can be Excel dates or text in DateFormat.
+type XLSXSystemRepository struct {
+	FilePath   string
+	Sheet      string // Worksheet holding the transactions, defaults to the first one
+	HeaderRow  int    // Row number of the header, defaults to 1
+	DateFormat string
+	NumWorkers int
+	BatchSize  int
+
+	// RejectHandler receives the rows that couldn't be parsed, defaults to printing a warning
+	RejectHandler func(fileutil.Reject)
+}
+
+// NewXLSXSystemRepository creates a new XLSXSystemRepository reading the first sheet of the workbook
+func NewXLSXSystemRepository(fp, dateFormat string) *XLSXSystemRepository {
+	if dateFormat == "" {
+		dateFormat = "2006-01-02T15:04:05"
+	}
+
+	return &XLSXSystemRepository{
+		FilePath:      fp,
+		HeaderRow:     1,
+		DateFormat:    dateFormat,
+		NumWorkers:    4,
+		BatchSize:     1000,
+		RejectHandler: printRejectWarning,
+	}
+}
+
+func (r *XLSXSystemRepository) GetTransactionsInRange(ctx context.Context, startDate, endDate time.Time) ([]domain.SystemTransaction, error) {
+	txns, err := r.loader(startDate, endDate).Load(ctx, r.source())
+	if err != nil {
+		return nil, fmt.Errorf("reading and processing system transaction: %w", err)
+	}
+
+	return txns, nil
+}
+
+// GetTransactionsInRangeConcurrently reads the sheet on one goroutine and parses its rows concurrently.
+// Transactions are returned in the same order as they appear in the sheet.
+func (r *XLSXSystemRepository) GetTransactionsInRangeConcurrently(ctx context.Context, startDate, endDate time.Time) ([]domain.SystemTransaction, error) {
+	txns, err := r.loader(startDate, endDate).LoadConcurrently(ctx, r.source())
+	if err != nil {
+		return nil, fmt.Errorf("reading and processing system transaction: %w", err)
+	}
+
+	return txns, nil
+}
+
+// StreamTransactionsInRange yields the transactions made between startDate and endDate in sheet order
+func (r *XLSXSystemRepository) StreamTransactionsInRange(ctx context.Context, startDate, endDate time.Time) iter.Seq2[domain.SystemTransaction, error] {
+	txns := r.loader(startDate, endDate).StreamConcurrently(ctx, r.source())
+	return wrapStreamError(txns, "reading and processing system transaction")
+}
+
+func (r *XLSXSystemRepository) source() *fileutil.XLSXReader {
+	return &fileutil.XLSXReader{
+		FilePath:    r.FilePath,
+		Sheet:       r.Sheet,
+		HeaderRow:   r.HeaderRow,
+		DateColumns: []string{"transactionTime"},
+		DateLayout:  r.DateFormat,
+	}
+}
+
+func (r *XLSXSystemRepository) loader(startDate, endDate time.Time) *fileutil.Loader[domain.SystemTransaction] {
+	loader := newSystemLoader(r.DateFormat, startDate, endDate)
+	loader.Reject, loader.BatchSize, loader.Workers = r.RejectHandler, r.BatchSize, r.NumWorkers
+	return loader
+}
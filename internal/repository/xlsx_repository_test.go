@@ -0,0 +1,127 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/repository"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXLSXBankRepository_GetTransactionsInRange(t *testing.T) {
+	fp := writeTestXLSX(t, "bank_xyz.xlsx", [][]any{
+		{"unique_identifier", "amount", "date"},
+		{"BNK-1", 100000.5, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"BNK-2", -0.07, time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"BNK-3", 1234567.89, "2025-01-17"},
+		{"BNK-4", 10, time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)},
+	})
+
+	repo := repository.NewXLSXBankRepository(fp, "")
+
+	if repo.GetBankIdentifier() != "bank_xyz" {
+		t.Errorf("Expected bank identifier to be bank_xyz, got %s", repo.GetBankIdentifier())
+	}
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-15")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-17")
+
+	transactions, err := repo.GetTransactionsInRangeConcurrently(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		id     string
+		amount string
+		date   string
+	}{
+		{"BNK-1", "100000.5", "2025-01-15"},
+		{"BNK-2", "-0.07", "2025-01-16"},
+		{"BNK-3", "1234567.89", "2025-01-17"},
+	}
+
+	if len(transactions) != len(expected) {
+		t.Fatalf("Expected %d transactions, got %d", len(expected), len(transactions))
+	}
+
+	for i, want := range expected {
+		txn := transactions[i]
+		if txn.UniqID != want.id || !txn.Amount.Equal(decimal.RequireFromString(want.amount)) || txn.Date.Format("2006-01-02") != want.date {
+			t.Errorf("Expected %s %s on %s, got %s %s on %s",
+				want.id, want.amount, want.date, txn.UniqID, txn.Amount, txn.Date.Format("2006-01-02"))
+		}
+
+		if txn.BankID != "bank_xyz" {
+			t.Errorf("Expected bank ID bank_xyz, got %s", txn.BankID)
+		}
+	}
+}
+
+func TestXLSXSystemRepository_GetTransactionsInRange(t *testing.T) {
+	fp := writeTestXLSX(t, "ledger.xlsx", [][]any{
+		{"trxID", "amount", "type", "transactionTime"},
+		{"SYS-1", 51000.25, "DEBIT", time.Date(2025, 1, 16, 9, 15, 0, 0, time.UTC)},
+		{"SYS-2", 75000, "CREDIT", "2025-01-17T11:45:00"},
+		{"SYS-3", 10, "REFUND", time.Date(2025, 1, 17, 12, 0, 0, 0, time.UTC)}, // Rejected
+	})
+
+	var rejected int
+	repo := repository.NewXLSXSystemRepository(fp, "")
+	repo.RejectHandler = func(_ fileutil.Reject) { rejected++ }
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-01")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-31")
+
+	var transactions []domain.SystemTransaction
+	for txn, err := range repo.StreamTransactionsInRange(context.Background(), startDate, endDate) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		transactions = append(transactions, txn)
+	}
+
+	if len(transactions) != 2 {
+		t.Fatalf("Expected 2 transactions, got %d", len(transactions))
+	}
+
+	expectedTime := time.Date(2025, 1, 16, 9, 15, 0, 0, time.UTC)
+	if !transactions[0].TransactionTime.Equal(expectedTime) {
+		t.Errorf("Expected transaction time %s, got %s", expectedTime, transactions[0].TransactionTime)
+	}
+
+	if !transactions[0].Amount.Equal(decimal.RequireFromString("51000.25")) || transactions[0].Type != domain.Debit {
+		t.Errorf("Expected a 51000.25 debit, got %s %s", transactions[0].Amount, transactions[0].Type)
+	}
+
+	if rejected != 1 {
+		t.Errorf("Expected 1 rejected row, got %d", rejected)
+	}
+}
+
+// writeTestXLSX writes a workbook whose first sheet holds rows, starting at A1
+func writeTestXLSX(t *testing.T, name string, rows [][]any) string {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatalf("Failed to write row: %v", err)
+		}
+	}
+
+	fp := filepath.Join(t.TempDir(), name)
+	if err := f.SaveAs(fp); err != nil {
+		t.Fatalf("Failed to save workbook: %v", err)
+	}
+
+	return fp
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+// agingBuckets are the upper bounds in days of the aging buckets, the last one is open-ended
+var agingBuckets = []struct {
+	label   string
+	maxDays int
+}{
+	{"0-2", 2},
+	{"3-7", 7},
+	{"8-30", 30},
+	{">30", -1},
+}
+
+// buildAging ages the unmatched transactions of result, and the open items carried forward, at endDate
+func (s *ReconciliationService) buildAging(result domain.ReconciliationResult, endDate time.Time) domain.Aging {
+	builder := newAgingBuilder(endDate)
+
+	for _, txn := range result.UnMatchedSystemTxns {
+		builder.UnmatchedSystem(txn)
+	}
+
+	for _, txns := range result.UnMatchedBankTxns {
+		for _, txn := range txns {
+			builder.UnmatchedBank(txn)
+		}
+	}
+
+	if carried := result.CarriedForward; carried != nil {
+		for _, txn := range carried.OpenSystemTxns {
+			builder.addSystem(txn.SystemTransaction, txn.AgeDays)
+		}
+		for _, txn := range carried.OpenBankTxns {
+			builder.addBank(txn.BankTransaction, txn.AgeDays)
+		}
+	}
+
+	return builder.aging
+}
+
+// agingBuilder is a MatchSink aging the unmatched transactions at the end of a period as they're reported
+type agingBuilder struct {
+	endDay time.Time
+	aging  domain.Aging
+}
+
+func newAgingBuilder(endDate time.Time) *agingBuilder {
+	return &agingBuilder{
+		endDay: endDate.Truncate(24 * time.Hour),
+		aging: domain.Aging{
+			System: newAgingBuckets(),
+			Bank:   make(map[string]domain.AgingBuckets),
+		},
+	}
+}
+
+func (b *agingBuilder) Matched(domain.Match) error { return nil }
+
+func (b *agingBuilder) UnmatchedSystem(txn domain.SystemTransaction) error {
+	b.addSystem(txn, ageDays(txn.TransactionTime, b.endDay))
+	return nil
+}
+
+func (b *agingBuilder) UnmatchedBank(txn domain.BankTransaction) error {
+	b.addBank(txn, ageDays(txn.Date, b.endDay))
+	return nil
+}
+
+func (b *agingBuilder) addSystem(txn domain.SystemTransaction, age int) {
+	addToAgingBucket(b.aging.System, age, signedAmount(txn))
+}
+
+func (b *agingBuilder) addBank(txn domain.BankTransaction, age int) {
+	if _, found := b.aging.Bank[txn.BankID]; !found {
+		b.aging.Bank[txn.BankID] = newAgingBuckets()
+	}
+	addToAgingBucket(b.aging.Bank[txn.BankID], age, txn.Amount)
+}
+
+func newAgingBuckets() domain.AgingBuckets {
+	buckets := make(domain.AgingBuckets, len(agingBuckets))
+	for i, bucket := range agingBuckets {
+		buckets[i] = domain.AgingBucket{Bucket: bucket.label, Amount: decimal.Zero}
+	}
+	return buckets
+}
+
+// addToAgingBucket counts a transaction of the given age in its bucket
+func addToAgingBucket(buckets domain.AgingBuckets, age int, amount decimal.Decimal) {
+	i := 0
+	for i < len(agingBuckets)-1 && age > agingBuckets[i].maxDays {
+		i++
+	}
+
+	buckets[i].Count++
+	buckets[i].Amount = buckets[i].Amount.Add(amount)
+}
@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/matcher"
+	"github.com/tirasundara/reconciliation-service/internal/service"
+)
+
+func TestReconciliationService_Aging(t *testing.T) {
+	sysRepo := &MockSystemRepository{
+		transactions: []domain.SystemTransaction{
+			{TrxID: "SYS-1", Amount: decimal.NewFromInt(10), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-31T23:00:00")},
+			{TrxID: "SYS-2", Amount: decimal.NewFromInt(4), Type: domain.Debit, TransactionTime: parseTime(t, "2025-01-29T08:00:00")},
+			{TrxID: "SYS-3", Amount: decimal.NewFromInt(25), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-28T08:00:00")},
+			{TrxID: "SYS-4", Amount: decimal.NewFromInt(100), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-01T08:00:00")},
+		},
+	}
+
+	bankRepos := map[string]domain.BankTransactionRepository{
+		"Bank-ABC": &MockBankRepository{
+			BankID: "Bank-ABC",
+			transactions: []domain.BankTransaction{
+				{UniqID: "ABC-1", Amount: decimal.NewFromInt(-7), Date: parseTime(t, "2025-01-24"), BankID: "Bank-ABC"},
+				{UniqID: "ABC-2", Amount: decimal.NewFromInt(-8), Date: parseTime(t, "2025-01-23"), BankID: "Bank-ABC"},
+			},
+		},
+		"Bank-BCD": &MockBankRepository{
+			BankID: "Bank-BCD",
+			transactions: []domain.BankTransaction{
+				{UniqID: "BCD-1", Amount: decimal.NewFromInt(3), Date: parseTime(t, "2025-01-30"), BankID: "Bank-BCD"},
+			},
+		},
+	}
+
+	// A December item still open is aged too
+	openItems := &MockOpenItemRepository{
+		items: domain.OpenItems{
+			SystemTxns: []domain.SystemTransaction{
+				{TrxID: "SYS-DEC", Amount: decimal.NewFromInt(1000), Type: domain.Debit, TransactionTime: parseTime(t, "2024-12-01T08:00:00")},
+			},
+		},
+	}
+
+	svc := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewDefaultMatcher(), 1).WithOpenItems(openItems)
+
+	result, err := svc.Reconcile(context.Background(), parseTime(t, "2025-01-01"), parseTime(t, "2025-01-31"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		buckets  domain.AgingBuckets
+		expected []domain.AgingBucket
+	}{
+		{
+			name:    "system",
+			buckets: result.Aging.System,
+			expected: []domain.AgingBucket{
+				{Bucket: "0-2", Count: 2, Amount: decimal.NewFromInt(6)},
+				{Bucket: "3-7", Count: 1, Amount: decimal.NewFromInt(25)},
+				{Bucket: "8-30", Count: 1, Amount: decimal.NewFromInt(100)},
+				{Bucket: ">30", Count: 1, Amount: decimal.NewFromInt(-1000)},
+			},
+		},
+		{
+			name:    "Bank-ABC",
+			buckets: result.Aging.Bank["Bank-ABC"],
+			expected: []domain.AgingBucket{
+				{Bucket: "0-2", Amount: decimal.Zero},
+				{Bucket: "3-7", Count: 1, Amount: decimal.NewFromInt(-7)},
+				{Bucket: "8-30", Count: 1, Amount: decimal.NewFromInt(-8)},
+				{Bucket: ">30", Amount: decimal.Zero},
+			},
+		},
+		{
+			name:    "Bank-BCD",
+			buckets: result.Aging.Bank["Bank-BCD"],
+			expected: []domain.AgingBucket{
+				{Bucket: "0-2", Count: 1, Amount: decimal.NewFromInt(3)},
+				{Bucket: "3-7", Amount: decimal.Zero},
+				{Bucket: "8-30", Amount: decimal.Zero},
+				{Bucket: ">30", Amount: decimal.Zero},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if len(tt.buckets) != len(tt.expected) {
+			t.Fatalf("%s: expected %d buckets, got %d", tt.name, len(tt.expected), len(tt.buckets))
+		}
+
+		for i, want := range tt.expected {
+			got := tt.buckets[i]
+			if got.Bucket != want.Bucket || got.Count != want.Count || !got.Amount.Equal(want.Amount) {
+				t.Errorf("%s: expected %s to hold %d for %s, got %s holding %d for %s",
+					tt.name, want.Bucket, want.Count, want.Amount, got.Bucket, got.Count, got.Amount)
+			}
+		}
+	}
+}
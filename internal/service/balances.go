@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+// WithBalances checks the opening and closing balances of repo against the transactions of the period, and
+// reconciles the bank balance with the book balance
+func (s *ReconciliationService) WithBalances(repo domain.BalanceRepository) *ReconciliationService {
+	s.balances = repo
+	return s
+}
+
+// reconcileBalances checks the balances of the period between startDate and endDate, and builds the bank to
+// book statement at endDate when the balances of the book and of every bank are known
+func (s *ReconciliationService) reconcileBalances(ctx context.Context, startDate, endDate time.Time, result *domain.ReconciliationResult) error {
+	if s.balances == nil {
+		return nil
+	}
+
+	balances, err := s.balances.GetBalances(ctx, startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("fetching balances: %w", err)
+	}
+
+	startDay := startDate.Truncate(24 * time.Hour)
+	endDay := endDate.Truncate(24 * time.Hour)
+	inPeriod := func(t time.Time) bool {
+		day := t.Truncate(24 * time.Hour)
+		return !day.Before(startDay) && !day.After(endDay)
+	}
+
+	// The transactions dated in the period, a match by the date buffer can pair one of another period
+	var bookTxns domain.Total
+	bankTxns := make(map[string]domain.Total)
+	addBankTxn := func(txn domain.BankTransaction) {
+		if inPeriod(txn.Date) {
+			total := bankTxns[txn.BankID]
+			total.Add(txn.Amount)
+			bankTxns[txn.BankID] = total
+		}
+	}
+	addSystemTxn := func(txn domain.SystemTransaction) {
+		if inPeriod(txn.TransactionTime) {
+			bookTxns.Add(signedAmount(txn))
+		}
+	}
+
+	for _, match := range result.MatchedTxns {
+		addSystemTxn(match.SystemTxn)
+		addBankTxn(match.BankTxn)
+	}
+	for _, txn := range result.UnMatchedSystemTxns {
+		addSystemTxn(txn)
+	}
+	for _, txns := range result.UnMatchedBankTxns {
+		for _, txn := range txns {
+			addBankTxn(txn)
+		}
+	}
+
+	// The other matches clearing open items are already counted
+	for _, match := range clearingMatches(*result) {
+		addSystemTxn(match.SystemTxn)
+		addBankTxn(match.BankTxn)
+	}
+
+	reconciliation := &domain.BalanceReconciliation{Bank: make(map[string]domain.BalanceCheck)}
+	for bankID, balance := range balances.Bank {
+		reconciliation.Bank[bankID] = checkBalance(balance, bankTxns[bankID])
+	}
+
+	if balances.Book != nil {
+		check := checkBalance(*balances.Book, bookTxns)
+		reconciliation.Book = &check
+
+		reconciliation.Statement = s.buildStatement(*result, balances, endDay)
+	}
+
+	result.Balances = reconciliation
+	return nil
+}
+
+// checkBalance checks that the opening balance plus the transactions make the closing balance
+func checkBalance(balance domain.StatementBalance, txns domain.Total) domain.BalanceCheck {
+	difference := balance.Closing.Sub(balance.Opening).Sub(txns.Amount)
+
+	return domain.BalanceCheck{
+		Account:      balance.Account,
+		Opening:      balance.Opening,
+		Transactions: txns,
+		Closing:      balance.Closing,
+		Difference:   difference,
+		Balanced:     difference.IsZero(),
+	}
+}
+
+// buildStatement reconciles the closing balances of the banks with the closing balance of the book, or returns
+// nil when the balances of a bank are missing
+func (s *ReconciliationService) buildStatement(result domain.ReconciliationResult, balances domain.Balances, endDay time.Time) *domain.BankToBookStatement {
+	statement := &domain.BankToBookStatement{BookBalance: balances.Book.Closing}
+
+	banks := make(map[string]bool)
+	for _, repo := range s.bankRepos {
+		banks[repo.GetBankIdentifier()] = true
+	}
+
+	for bankID := range banks {
+		balance, found := balances.Bank[bankID]
+		if !found {
+			return nil
+		}
+		statement.BankBalance = statement.BankBalance.Add(balance.Closing)
+	}
+
+	addInTransit := func(txn domain.SystemTransaction) {
+		if txn.Type == domain.Debit {
+			statement.OutstandingPayments.Add(signedAmount(txn))
+		} else {
+			statement.DepositsInTransit.Add(signedAmount(txn))
+		}
+	}
+
+	// The bank side of a match by the date buffer can come after the period
+	for _, match := range result.MatchedTxns {
+		if match.BankTxn.Date.Truncate(24 * time.Hour).After(endDay) {
+			addInTransit(match.SystemTxn)
+		}
+	}
+
+	for _, txn := range result.UnMatchedSystemTxns {
+		addInTransit(txn)
+	}
+	for _, txns := range result.UnMatchedBankTxns {
+		for _, txn := range txns {
+			statement.BankOnlyItems.Add(txn.Amount)
+		}
+	}
+
+	// The open items of past periods are still missing from the other side
+	if result.CarriedForward != nil {
+		for _, item := range result.CarriedForward.OpenSystemTxns {
+			addInTransit(item.SystemTransaction)
+		}
+		for _, item := range result.CarriedForward.OpenBankTxns {
+			statement.BankOnlyItems.Add(item.Amount)
+		}
+	}
+
+	statement.AdjustedBankBalance = statement.BankBalance.Add(statement.DepositsInTransit.Amount).Add(statement.OutstandingPayments.Amount)
+	statement.AdjustedBookBalance = statement.BookBalance.Add(statement.BankOnlyItems.Amount)
+	statement.Difference = statement.AdjustedBankBalance.Sub(statement.AdjustedBookBalance)
+
+	return statement
+}
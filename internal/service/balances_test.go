@@ -0,0 +1,177 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/matcher"
+	"github.com/tirasundara/reconciliation-service/internal/service"
+)
+
+type MockBalanceRepository struct {
+	balances domain.Balances
+}
+
+func (m *MockBalanceRepository) GetBalances(ctx context.Context, startDate, endDate time.Time) (domain.Balances, error) {
+	return m.balances, nil
+}
+
+func newBalancesTestService(t *testing.T, balances domain.Balances) *service.ReconciliationService {
+	sysRepo := &MockSystemRepository{
+		transactions: []domain.SystemTransaction{
+			{TrxID: "SYS-1", Amount: decimal.NewFromInt(100), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-10T09:00:00")},
+			{TrxID: "SYS-2", Amount: decimal.NewFromInt(50), Type: domain.Debit, TransactionTime: parseTime(t, "2025-01-15T09:00:00")},
+			{TrxID: "SYS-3", Amount: decimal.NewFromInt(30), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-31T22:00:00")},
+			{TrxID: "SYS-4", Amount: decimal.NewFromInt(20), Type: domain.Debit, TransactionTime: parseTime(t, "2025-01-20T09:00:00")},
+			{TrxID: "SYS-5", Amount: decimal.NewFromInt(40), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-25T09:00:00")},
+		},
+	}
+
+	bankRepos := map[string]domain.BankTransactionRepository{
+		"Bank-ABC": &MockBankRepository{
+			BankID: "Bank-ABC",
+			transactions: []domain.BankTransaction{
+				{UniqID: "ABC-1", Amount: decimal.NewFromInt(100), Date: parseTime(t, "2025-01-10"), BankID: "Bank-ABC"},
+				{UniqID: "ABC-2", Amount: decimal.RequireFromString("-49.95"), Date: parseTime(t, "2025-01-15"), BankID: "Bank-ABC"},
+				{UniqID: "ABC-3", Amount: decimal.NewFromInt(30), Date: parseTime(t, "2025-02-01"), BankID: "Bank-ABC"},
+				{UniqID: "ABC-4", Amount: decimal.NewFromInt(-5), Date: parseTime(t, "2025-01-31"), BankID: "Bank-ABC"},
+			},
+		},
+		"Bank-BCD": &MockBankRepository{
+			BankID: "Bank-BCD",
+			transactions: []domain.BankTransaction{
+				{UniqID: "BCD-1", Amount: decimal.NewFromInt(12), Date: parseTime(t, "2025-01-12"), BankID: "Bank-BCD"},
+			},
+		},
+	}
+
+	m := matcher.NewDefaultMatcher(
+		matcher.NewExactMatchStrategy(),
+		matcher.NewFuzzyMatchStrategy(0.1),
+		matcher.NewDateBufferMatchStrategy(1),
+	)
+
+	return service.NewReconciliationService(sysRepo, bankRepos, m, 1).WithBalances(&MockBalanceRepository{balances: balances})
+}
+
+func TestReconciliationService_Balances(t *testing.T) {
+	book := domain.StatementBalance{Opening: decimal.NewFromInt(500), Closing: decimal.NewFromInt(600)}
+	svc := newBalancesTestService(t, domain.Balances{
+		Book: &book,
+		Bank: map[string]domain.StatementBalance{
+			"Bank-ABC": {Account: "123", Opening: decimal.NewFromInt(500), Closing: decimal.RequireFromString("545.05")},
+			"Bank-BCD": {Account: "456", Opening: decimal.Zero, Closing: decimal.NewFromInt(20)}, // 8 short
+		},
+	})
+
+	result, err := svc.Reconcile(context.Background(), parseTime(t, "2025-01-01"), parseTime(t, "2025-01-31"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Balances == nil {
+		t.Fatal("Expected the balances to be reconciled, got nil")
+	}
+
+	checks := []struct {
+		name       string
+		check      domain.BalanceCheck
+		count      int
+		difference decimal.Decimal
+	}{
+		{"Bank-ABC", result.Balances.Bank["Bank-ABC"], 3, decimal.Zero}, // ABC-3 is dated after the period
+		{"Bank-BCD", result.Balances.Bank["Bank-BCD"], 1, decimal.NewFromInt(8)},
+		{"book", *result.Balances.Book, 5, decimal.Zero},
+	}
+
+	for _, tt := range checks {
+		if tt.check.Transactions.Count != tt.count || !tt.check.Difference.Equal(tt.difference) || tt.check.Balanced != tt.difference.IsZero() {
+			t.Errorf("%s: expected %d transactions and a difference of %s, got %+v", tt.name, tt.count, tt.difference, tt.check)
+		}
+	}
+
+	statement := result.Balances.Statement
+	if statement == nil {
+		t.Fatal("Expected a bank to book statement, got nil")
+	}
+
+	amounts := []struct {
+		name     string
+		got      decimal.Decimal
+		expected string
+	}{
+		{"bank balance", statement.BankBalance, "565.05"},
+		{"deposits in transit", statement.DepositsInTransit.Amount, "70"}, // SYS-5, and SYS-3 cleared on Feb 1
+		{"outstanding payments", statement.OutstandingPayments.Amount, "-20"},
+		{"adjusted bank balance", statement.AdjustedBankBalance, "615.05"},
+		{"bank-only items", statement.BankOnlyItems.Amount, "7"},
+		{"adjusted book balance", statement.AdjustedBookBalance, "607"},
+		{"difference", statement.Difference, "8.05"}, // The missing Bank-BCD transactions and the fuzzy match
+	}
+
+	for _, tt := range amounts {
+		if !tt.got.Equal(decimal.RequireFromString(tt.expected)) {
+			t.Errorf("Expected %s %s, got %s", tt.name, tt.expected, tt.got)
+		}
+	}
+}
+
+func TestReconciliationService_BalancesAcrossPeriodBoundary(t *testing.T) {
+	book := domain.StatementBalance{Opening: decimal.NewFromInt(600), Closing: decimal.NewFromInt(600)}
+	svc := newBalancesTestService(t, domain.Balances{
+		Book: &book,
+		Bank: map[string]domain.StatementBalance{
+			"Bank-ABC": {Opening: decimal.RequireFromString("545.05"), Closing: decimal.RequireFromString("575.05")},
+			"Bank-BCD": {Opening: decimal.NewFromInt(20), Closing: decimal.NewFromInt(20)},
+		},
+	})
+
+	// SYS-3 of January 31 is matched by the date buffer to ABC-3 of February 1
+	result, err := svc.Reconcile(context.Background(), parseTime(t, "2025-02-01"), parseTime(t, "2025-02-28"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.MatchedTxns) != 1 || result.MatchedTxns[0].BankTxn.UniqID != "ABC-3" {
+		t.Fatalf("Expected ABC-3 matched across the period boundary, got %+v", result.MatchedTxns)
+	}
+
+	// Only the bank side is a transaction of February, and it's already in the book
+	if check := result.Balances.Bank["Bank-ABC"]; check.Transactions.Count != 1 || !check.Balanced {
+		t.Errorf("Expected ABC-3 to balance Bank-ABC, got %+v", check)
+	}
+
+	if check := result.Balances.Book; check.Transactions.Count != 0 || !check.Balanced {
+		t.Errorf("Expected no book transaction in February, got %+v", check)
+	}
+
+	if items := result.Balances.Statement.BankOnlyItems; items.Count != 0 {
+		t.Errorf("Expected no bank-only item, got %+v", items)
+	}
+}
+
+func TestReconciliationService_BalancesMissingBank(t *testing.T) {
+	book := domain.StatementBalance{Opening: decimal.NewFromInt(500), Closing: decimal.NewFromInt(600)}
+	svc := newBalancesTestService(t, domain.Balances{
+		Book: &book,
+		Bank: map[string]domain.StatementBalance{
+			"Bank-ABC": {Opening: decimal.NewFromInt(500), Closing: decimal.RequireFromString("545.05")},
+		},
+	})
+
+	result, err := svc.Reconcile(context.Background(), parseTime(t, "2025-01-01"), parseTime(t, "2025-01-31"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Balances.Book == nil || len(result.Balances.Bank) != 1 {
+		t.Errorf("Expected the book and Bank-ABC to be checked, got %+v", result.Balances)
+	}
+
+	if result.Balances.Statement != nil {
+		t.Errorf("Expected no statement without the Bank-BCD balances, got %+v", result.Balances.Statement)
+	}
+}
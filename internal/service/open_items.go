@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+// WithOpenItems carries the unmatched transactions forward from one period to the next in repo: the items
+// left open by past periods are cleared by the transactions of the period reconciled, instead of those being
+// reported as new exceptions, and the period's unmatched transactions are kept open for the next ones
+func (s *ReconciliationService) WithOpenItems(repo domain.OpenItemRepository) *ReconciliationService {
+	s.openItems = repo
+	return s
+}
+
+// carryForward clears the open items of past periods with the unmatched transactions of result, reports the
+// items still open with their age at endDate, and updates the open item repository
+func (s *ReconciliationService) carryForward(ctx context.Context, startDate, endDate time.Time, result *domain.ReconciliationResult) error {
+	if s.openItems == nil {
+		return nil
+	}
+
+	startDay := startDate.Truncate(24 * time.Hour)
+	open, err := s.openItems.GetOpenItems(ctx, startDay)
+	if err != nil {
+		return fmt.Errorf("fetching open items: %w", err)
+	}
+
+	carried := &domain.CarriedForward{}
+	endDay := endDate.Truncate(24 * time.Hour)
+
+	// An open item of the day before the period can already be matched by the date buffer, the others are
+	// cleared by the unmatched transactions of the period
+	matchedSystemTxns := make(map[string]domain.Match)
+	matchedBankTxns := make(map[string]domain.Match)
+	for _, match := range result.MatchedTxns {
+		matchedSystemTxns[match.SystemTxn.TrxID] = match
+		matchedBankTxns[bankKey(match.BankTxn)] = match
+	}
+
+	var openSystemTxns []domain.SystemTransaction
+	for _, item := range open.SystemTxns {
+		if match, found := matchedSystemTxns[item.TrxID]; found {
+			carried.Cleared = append(carried.Cleared, match)
+			continue
+		}
+		openSystemTxns = append(openSystemTxns, item)
+	}
+
+	var unmatchedBankTxns []domain.BankTransaction
+	for _, bankID := range slices.Sorted(maps.Keys(result.UnMatchedBankTxns)) {
+		unmatchedBankTxns = append(unmatchedBankTxns, result.UnMatchedBankTxns[bankID]...)
+	}
+
+	cleared, err := s.clearOpenItems(ctx, openSystemTxns, unmatchedBankTxns, startDay)
+	if err != nil {
+		return err
+	}
+	carried.Cleared = append(carried.Cleared, cleared...)
+
+	clearedSystemIDs, clearedBankKeys := clearedKeys(cleared)
+	for bankID, txns := range result.UnMatchedBankTxns {
+		txns = slices.DeleteFunc(txns, func(txn domain.BankTransaction) bool { return clearedBankKeys[bankKey(txn)] })
+		if len(txns) == 0 {
+			delete(result.UnMatchedBankTxns, bankID)
+			continue
+		}
+		result.UnMatchedBankTxns[bankID] = txns
+	}
+
+	for _, item := range openSystemTxns {
+		if !clearedSystemIDs[item.TrxID] {
+			carried.OpenSystemTxns = append(carried.OpenSystemTxns, domain.AgedSystemTransaction{
+				SystemTransaction: item,
+				AgeDays:           ageDays(item.TransactionTime, endDay),
+			})
+		}
+	}
+
+	var openBankTxns []domain.BankTransaction
+	for _, item := range open.BankTxns {
+		if match, found := matchedBankTxns[bankKey(item)]; found {
+			carried.Cleared = append(carried.Cleared, match)
+			continue
+		}
+		openBankTxns = append(openBankTxns, item)
+	}
+
+	cleared, err = s.clearOpenItems(ctx, result.UnMatchedSystemTxns, openBankTxns, startDay)
+	if err != nil {
+		return err
+	}
+	carried.Cleared = append(carried.Cleared, cleared...)
+
+	clearedSystemIDs, clearedBankKeys = clearedKeys(cleared)
+	result.UnMatchedSystemTxns = slices.DeleteFunc(result.UnMatchedSystemTxns, func(txn domain.SystemTransaction) bool {
+		return clearedSystemIDs[txn.TrxID]
+	})
+
+	for _, item := range openBankTxns {
+		if !clearedBankKeys[bankKey(item)] {
+			carried.OpenBankTxns = append(carried.OpenBankTxns, domain.AgedBankTransaction{
+				BankTransaction: item,
+				AgeDays:         ageDays(item.Date, endDay),
+			})
+		}
+	}
+
+	opened := domain.OpenItems{SystemTxns: result.UnMatchedSystemTxns}
+	for _, bankID := range slices.Sorted(maps.Keys(result.UnMatchedBankTxns)) {
+		opened.BankTxns = append(opened.BankTxns, result.UnMatchedBankTxns[bankID]...)
+	}
+
+	closed := slices.Concat(result.MatchedTxns, carried.Cleared)
+	if err := s.openItems.UpdateOpenItems(ctx, endDay, closed, opened); err != nil {
+		return fmt.Errorf("updating open items: %w", err)
+	}
+
+	result.CarriedForward = carried
+	return nil
+}
+
+// clearOpenItems matches system and bank transactions, one side being open items of past periods, with the
+// service's matcher, so open items clear by the same strategies and tolerances as the transactions of a period.
+// An open item is late by nature, so both sides are given to the matcher as of day and only their amounts
+// decide. The matches returned hold the transactions with their own dates
+func (s *ReconciliationService) clearOpenItems(ctx context.Context, systemTxns []domain.SystemTransaction, bankTxns []domain.BankTransaction, day time.Time) ([]domain.Match, error) {
+	if len(systemTxns) == 0 || len(bankTxns) == 0 {
+		return nil, nil
+	}
+
+	systemByID := make(map[string]domain.SystemTransaction, len(systemTxns))
+	sameDaySystemTxns := make([]domain.SystemTransaction, 0, len(systemTxns))
+	for _, txn := range systemTxns {
+		systemByID[txn.TrxID] = txn
+		txn.TransactionTime = day
+		sameDaySystemTxns = append(sameDaySystemTxns, txn)
+	}
+
+	bankByKey := make(map[string]domain.BankTransaction, len(bankTxns))
+	sameDayBankTxns := make([]domain.BankTransaction, 0, len(bankTxns))
+	for _, txn := range bankTxns {
+		bankByKey[bankKey(txn)] = txn
+		txn.Date = day
+		sameDayBankTxns = append(sameDayBankTxns, txn)
+	}
+
+	matches, err := s.matcher.FindMatches(ctx, sameDaySystemTxns, sameDayBankTxns)
+	if err != nil {
+		return nil, fmt.Errorf("matching open items: %w", err)
+	}
+
+	for i := range matches {
+		matches[i].SystemTxn = systemByID[matches[i].SystemTxn.TrxID]
+		matches[i].BankTxn = bankByKey[bankKey(matches[i].BankTxn)]
+	}
+
+	return matches, nil
+}
+
+// clearedKeys returns the IDs of the system transactions and the keys of the bank transactions of matches
+func clearedKeys(matches []domain.Match) (systemIDs, bankKeys map[string]bool) {
+	systemIDs, bankKeys = make(map[string]bool), make(map[string]bool)
+	for _, match := range matches {
+		systemIDs[match.SystemTxn.TrxID] = true
+		bankKeys[bankKey(match.BankTxn)] = true
+	}
+	return systemIDs, bankKeys
+}
+
+// clearingMatches returns the matches clearing an open item that aren't matches of the period too, as the open
+// items matched by the date buffer are
+func clearingMatches(result domain.ReconciliationResult) []domain.Match {
+	if result.CarriedForward == nil {
+		return nil
+	}
+
+	matchedBankKeys := make(map[string]bool, len(result.MatchedTxns))
+	for _, match := range result.MatchedTxns {
+		matchedBankKeys[bankKey(match.BankTxn)] = true
+	}
+
+	var matches []domain.Match
+	for _, match := range result.CarriedForward.Cleared {
+		if !matchedBankKeys[bankKey(match.BankTxn)] {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// signedAmount returns the amount of a system transaction as it appears on a bank statement, debits being negative
+func signedAmount(txn domain.SystemTransaction) decimal.Decimal {
+	if txn.Type == domain.Debit {
+		return txn.Amount.Neg()
+	}
+	return txn.Amount
+}
+
+func bankKey(txn domain.BankTransaction) string {
+	return fmt.Sprintf("%s-%s", txn.BankID, txn.UniqID)
+}
+
+// ageDays returns the number of days from the day of t to endDay
+func ageDays(t, endDay time.Time) int {
+	return int(endDay.Sub(t.Truncate(24*time.Hour)).Hours() / 24)
+}
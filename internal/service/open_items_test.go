@@ -0,0 +1,174 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/matcher"
+	"github.com/tirasundara/reconciliation-service/internal/service"
+)
+
+type MockOpenItemRepository struct {
+	items  domain.OpenItems
+	closed []domain.Match
+	opened domain.OpenItems
+}
+
+func (m *MockOpenItemRepository) GetOpenItems(ctx context.Context, before time.Time) (domain.OpenItems, error) {
+	return m.items, nil
+}
+
+func (m *MockOpenItemRepository) UpdateOpenItems(ctx context.Context, periodEnd time.Time, closed []domain.Match, opened domain.OpenItems) error {
+	m.closed, m.opened = closed, opened
+	return nil
+}
+
+func TestReconciliationService_CarryForward(t *testing.T) {
+	openItems := &MockOpenItemRepository{
+		items: domain.OpenItems{
+			SystemTxns: []domain.SystemTransaction{
+				{TrxID: "SYS-OLD", Amount: decimal.NewFromInt(42), Type: domain.Credit, TransactionTime: parseTime(t, "2024-12-20T10:00:00")},
+				{TrxID: "SYS-CHQ", Amount: decimal.NewFromInt(500), Type: domain.Debit, TransactionTime: parseTime(t, "2025-01-30T10:00:00")},
+			},
+			BankTxns: []domain.BankTransaction{
+				{UniqID: "BNK-DEP", Amount: decimal.NewFromInt(70), Date: parseTime(t, "2025-01-29"), BankID: "Bank-ABC"},
+			},
+		},
+	}
+
+	sysRepo := &MockSystemRepository{
+		transactions: []domain.SystemTransaction{
+			{TrxID: "SYS-1", Amount: decimal.NewFromInt(100), Type: domain.Credit, TransactionTime: parseTime(t, "2025-02-01T09:00:00")},
+			{TrxID: "SYS-DEP", Amount: decimal.NewFromInt(70), Type: domain.Credit, TransactionTime: parseTime(t, "2025-02-03T09:00:00")},
+			{TrxID: "SYS-2", Amount: decimal.NewFromInt(5), Type: domain.Debit, TransactionTime: parseTime(t, "2025-02-10T09:00:00")},
+		},
+	}
+
+	bankRepos := map[string]domain.BankTransactionRepository{
+		"Bank-ABC": &MockBankRepository{
+			BankID: "Bank-ABC",
+			transactions: []domain.BankTransaction{
+				{UniqID: "BNK-1", Amount: decimal.NewFromInt(100), Date: parseTime(t, "2025-02-01"), BankID: "Bank-ABC"},
+				{UniqID: "BNK-CHQ", Amount: decimal.NewFromInt(-500), Date: parseTime(t, "2025-02-02"), BankID: "Bank-ABC"},
+			},
+		},
+	}
+
+	svc := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewDefaultMatcher(), 1).WithOpenItems(openItems)
+
+	result, err := svc.Reconcile(context.Background(), parseTime(t, "2025-02-01"), parseTime(t, "2025-02-28"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	carried := result.CarriedForward
+	if carried == nil {
+		t.Fatalf("Expected the open items to be carried forward")
+	}
+
+	// The cheque and the deposit of January clear the transactions of February that would be exceptions
+	if len(carried.Cleared) != 2 {
+		t.Fatalf("Expected 2 cleared open items, got %d", len(carried.Cleared))
+	}
+
+	if carried.Cleared[0].SystemTxn.TrxID != "SYS-CHQ" || carried.Cleared[0].BankTxn.UniqID != "BNK-CHQ" {
+		t.Errorf("Expected SYS-CHQ cleared by BNK-CHQ, got %s and %s", carried.Cleared[0].SystemTxn.TrxID, carried.Cleared[0].BankTxn.UniqID)
+	}
+
+	if carried.Cleared[1].SystemTxn.TrxID != "SYS-DEP" || carried.Cleared[1].BankTxn.UniqID != "BNK-DEP" {
+		t.Errorf("Expected BNK-DEP cleared by SYS-DEP, got %s and %s", carried.Cleared[1].BankTxn.UniqID, carried.Cleared[1].SystemTxn.TrxID)
+	}
+
+	if len(result.UnMatchedSystemTxns) != 1 || result.UnMatchedSystemTxns[0].TrxID != "SYS-2" {
+		t.Errorf("Expected SYS-2 to be the only new unmatched system transaction, got %+v", result.UnMatchedSystemTxns)
+	}
+
+	if len(result.UnMatchedBankTxns) != 0 {
+		t.Errorf("Expected no unmatched bank transaction, got %+v", result.UnMatchedBankTxns)
+	}
+
+	if len(carried.OpenSystemTxns) != 1 || carried.OpenSystemTxns[0].TrxID != "SYS-OLD" || carried.OpenSystemTxns[0].AgeDays != 70 {
+		t.Errorf("Expected SYS-OLD still open at 70 days, got %+v", carried.OpenSystemTxns)
+	}
+
+	// The repository closes the matches of the period and the cleared items, and opens the new exceptions
+	if len(openItems.closed) != 3 {
+		t.Errorf("Expected 3 closed matches, got %d", len(openItems.closed))
+	}
+
+	if len(openItems.opened.SystemTxns) != 1 || len(openItems.opened.BankTxns) != 0 {
+		t.Errorf("Expected SYS-2 to be opened, got %+v", openItems.opened)
+	}
+}
+
+func TestReconciliationService_CarryForwardWithinTolerance(t *testing.T) {
+	openItems := &MockOpenItemRepository{
+		items: domain.OpenItems{
+			SystemTxns: []domain.SystemTransaction{
+				{TrxID: "SYS-CHQ", Amount: decimal.NewFromInt(100), Type: domain.Debit, TransactionTime: parseTime(t, "2025-01-28T10:00:00")},
+			},
+		},
+	}
+
+	sysRepo := &MockSystemRepository{}
+	bankRepos := map[string]domain.BankTransactionRepository{
+		"Bank-ABC": &MockBankRepository{
+			BankID: "Bank-ABC",
+			transactions: []domain.BankTransaction{
+				{UniqID: "BNK-CHQ", Amount: decimal.RequireFromString("-99.99"), Date: parseTime(t, "2025-02-04"), BankID: "Bank-ABC"},
+			},
+		},
+	}
+
+	svc := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewDefaultMatcher(), 1).WithOpenItems(openItems)
+
+	result, err := svc.Reconcile(context.Background(), parseTime(t, "2025-02-01"), parseTime(t, "2025-02-28"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The cheque clears like a transaction of the period would, by the fuzzy strategy
+	cleared := result.CarriedForward.Cleared
+	if len(cleared) != 1 || cleared[0].SystemTxn.TrxID != "SYS-CHQ" || cleared[0].BankTxn.UniqID != "BNK-CHQ" {
+		t.Fatalf("Expected SYS-CHQ cleared by BNK-CHQ, got %+v", cleared)
+	}
+
+	if cleared[0].Strategy != "fuzzy" {
+		t.Errorf("Expected the fuzzy strategy, got %s", cleared[0].Strategy)
+	}
+
+	if !cleared[0].AmmountDiff.Equal(decimal.RequireFromString("0.01")) {
+		t.Errorf("Expected a difference of 0.01, got %s", cleared[0].AmmountDiff)
+	}
+
+	// The matched transactions keep their own dates
+	if !cleared[0].SystemTxn.TransactionTime.Equal(parseTime(t, "2025-01-28T10:00:00")) || !cleared[0].BankTxn.Date.Equal(parseTime(t, "2025-02-04")) {
+		t.Errorf("Expected the original dates, got %v and %v", cleared[0].SystemTxn.TransactionTime, cleared[0].BankTxn.Date)
+	}
+
+	if len(result.UnMatchedBankTxns) != 0 || len(result.CarriedForward.OpenSystemTxns) != 0 {
+		t.Errorf("Expected nothing left open, got %+v and %+v", result.UnMatchedBankTxns, result.CarriedForward.OpenSystemTxns)
+	}
+
+	if !result.Summary.ClearedOpenItems.Amount.Equal(decimal.RequireFromString("-99.99")) {
+		t.Errorf("Expected the bank side counted as cleared, got %s", result.Summary.ClearedOpenItems.Amount)
+	}
+}
+
+func TestReconciliationService_WithoutOpenItems(t *testing.T) {
+	sysRepo, bankRepos := newTestRepositories(t)
+
+	svc := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewDefaultMatcher(), 1)
+
+	result, err := svc.Reconcile(context.Background(), parseTime(t, "2025-01-15"), parseTime(t, "2025-01-20"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.CarriedForward != nil {
+		t.Errorf("Expected no carried forward report, got %+v", result.CarriedForward)
+	}
+}
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -11,9 +14,11 @@ import (
 // ReconciliationService orchestrates the reconciliation process
 type ReconciliationService struct {
 	systemRepo domain.SystemTransactionRepository
-	bankRepos  map[string]domain.BankTransactionRepository
+	bankRepos  map[string]domain.BankTransactionRepository // Keyed by source, several sources can belong to the same bank
 	matcher    domain.TransactionMatcher
 	dateBuffer int
+	openItems  domain.OpenItemRepository // Optional, see WithOpenItems
+	balances   domain.BalanceRepository  // Optional, see WithBalances
 }
 
 // NewReconciliationService creates a new ReconciliationService
@@ -31,23 +36,25 @@ func NewReconciliationService(
 	}
 }
 
-// Reconcile performs the reconciliation process for the given date range
-func (s *ReconciliationService) Reconcile(startDate, endDate time.Time) (domain.ReconciliationResult, error) {
+// Reconcile performs the reconciliation process for the given date range.
+// Cancelling ctx aborts loading and matching, and Reconcile returns the context's error.
+func (s *ReconciliationService) Reconcile(ctx context.Context, startDate, endDate time.Time) (domain.ReconciliationResult, error) {
 
 	// Calculate effective date range with buffer
 	effectiveStartDate := startDate.AddDate(0, 0, -s.dateBuffer)
 	effectiveEndDate := endDate.AddDate(0, 0, s.dateBuffer)
 
 	// Get system txns
-	systemTxns, err := s.systemRepo.GetTransactionsInRangeConcurrently(effectiveStartDate, effectiveEndDate)
+	systemTxns, err := s.systemRepo.GetTransactionsInRangeConcurrently(ctx, effectiveStartDate, effectiveEndDate)
 	if err != nil {
 		return domain.ReconciliationResult{}, fmt.Errorf("fetching system transactions: %w", err)
 	}
 
 	// Get bank txns -- from all bank repositories
 	var allBankTxns []domain.BankTransaction
-	for _, repo := range s.bankRepos {
-		bankTxns, err := repo.GetTransactionsInRangeConcurrently(effectiveStartDate, effectiveEndDate)
+	// Sources are loaded in key order, so the matches don't depend on map iteration order
+	for _, source := range slices.Sorted(maps.Keys(s.bankRepos)) {
+		bankTxns, err := s.bankRepos[source].GetTransactionsInRangeConcurrently(ctx, effectiveStartDate, effectiveEndDate)
 		if err != nil {
 			return domain.ReconciliationResult{}, fmt.Errorf("fetching bank transactions: %w", err)
 		}
@@ -55,7 +62,7 @@ func (s *ReconciliationService) Reconcile(startDate, endDate time.Time) (domain.
 	}
 
 	// Find matches between system and bank txns
-	matches, err := s.matcher.FindMatches(systemTxns, allBankTxns)
+	matches, err := s.matcher.FindMatches(ctx, systemTxns, allBankTxns)
 	if err != nil {
 		return domain.ReconciliationResult{}, fmt.Errorf("matching transactions: %w", err)
 	}
@@ -66,28 +73,53 @@ func (s *ReconciliationService) Reconcile(startDate, endDate time.Time) (domain.
 	unmatchedSystemTxns := s.findUnmatchedSystemTransactions(systemTxns, filteredMatches, startDate, endDate)
 	unmatchedBankTxns := s.findUnmatchedBankTransactions(allBankTxns, filteredMatches, startDate, endDate)
 
-	totalDiscrepancies := s.calculateTotalDiscrepancies(filteredMatches)
+	result := s.buildResult(filteredMatches, unmatchedSystemTxns, unmatchedBankTxns)
+	if err := s.completeResult(ctx, startDate, endDate, &result); err != nil {
+		return domain.ReconciliationResult{}, err
+	}
 
-	result := domain.ReconciliationResult{
-		TotalTxnsProcessed:  len(filteredMatches) + len(unmatchedSystemTxns) + s.countBankTransactions(unmatchedBankTxns),
-		MatchedTxns:         filteredMatches,
+	return result, nil
+}
+
+// completeResult carries the open items forward, checks the balances, totals the transactions and ages the ones
+// left unmatched
+func (s *ReconciliationService) completeResult(ctx context.Context, startDate, endDate time.Time, result *domain.ReconciliationResult) error {
+	if err := s.carryForward(ctx, startDate, endDate, result); err != nil {
+		return fmt.Errorf("carrying open items forward: %w", err)
+	}
+
+	if err := s.reconcileBalances(ctx, startDate, endDate, result); err != nil {
+		return fmt.Errorf("reconciling balances: %w", err)
+	}
+
+	result.Summary = s.buildSummary(*result, startDate)
+	result.Aging = s.buildAging(*result, endDate)
+	return nil
+}
+
+// buildResult assembles the result of a reconciliation from its matched and unmatched transactions
+func (s *ReconciliationService) buildResult(
+	matches []domain.Match,
+	unmatchedSystemTxns []domain.SystemTransaction,
+	unmatchedBankTxns map[string][]domain.BankTransaction,
+) domain.ReconciliationResult {
+	totalDiscrepancies := s.calculateTotalDiscrepancies(matches)
+
+	return domain.ReconciliationResult{
+		TotalTxnsProcessed:  2*len(matches) + len(unmatchedSystemTxns) + s.countBankTransactions(unmatchedBankTxns),
+		MatchedTxns:         matches,
 		UnMatchedSystemTxns: unmatchedSystemTxns,
 		UnMatchedBankTxns:   unmatchedBankTxns,
 		TotalDiscrepancies:  totalDiscrepancies,
 	}
-
-	return result, nil
 }
 
 func (s *ReconciliationService) filterMatchesByDateRange(matches []domain.Match, startDate, endDate time.Time) []domain.Match {
 	var filtered []domain.Match
 
-	startDay := startDate.Truncate(24 * time.Hour)
-	endDay := endDate.Truncate(24 * time.Hour)
-
+	// A match by the date buffer can pair a transaction of the period with one of the day before or after it
 	for _, match := range matches {
-		txnDay := match.SystemTxn.TransactionTime.Truncate(24 * time.Hour)
-		if (txnDay.Equal(startDay) || txnDay.After(startDay)) && (txnDay.Equal(endDay) || txnDay.Before(endDay)) {
+		if inPeriod(match.SystemTxn.TransactionTime, startDate, endDate) || inPeriod(match.BankTxn.Date, startDate, endDate) {
 			filtered = append(filtered, match)
 		}
 	}
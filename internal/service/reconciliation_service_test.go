@@ -1,6 +1,12 @@
 package service_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,32 +20,309 @@ type MockSystemRepository struct {
 	transactions []domain.SystemTransaction
 }
 
-func (m *MockSystemRepository) GetTransactionsInRange(startDate, endDate time.Time) ([]domain.SystemTransaction, error) {
+func (m *MockSystemRepository) GetTransactionsInRange(ctx context.Context, startDate, endDate time.Time) ([]domain.SystemTransaction, error) {
 	return m.transactions, nil
 }
 
-func (m *MockSystemRepository) GetTransactionsInRangeConcurrently(startDate, endDate time.Time) ([]domain.SystemTransaction, error) {
+func (m *MockSystemRepository) GetTransactionsInRangeConcurrently(ctx context.Context, startDate, endDate time.Time) ([]domain.SystemTransaction, error) {
 	return m.transactions, nil
 }
 
+func (m *MockSystemRepository) StreamTransactionsInRange(ctx context.Context, startDate, endDate time.Time) iter.Seq2[domain.SystemTransaction, error] {
+	return seqOf(m.transactions)
+}
+
 type MockBankRepository struct {
 	transactions []domain.BankTransaction
 	BankID       string
 }
 
-func (m *MockBankRepository) GetTransactionsInRange(startDate, endDate time.Time) ([]domain.BankTransaction, error) {
+func (m *MockBankRepository) GetTransactionsInRange(ctx context.Context, startDate, endDate time.Time) ([]domain.BankTransaction, error) {
 	return m.transactions, nil
 }
 
-func (m *MockBankRepository) GetTransactionsInRangeConcurrently(startDate, endDate time.Time) ([]domain.BankTransaction, error) {
+func (m *MockBankRepository) GetTransactionsInRangeConcurrently(ctx context.Context, startDate, endDate time.Time) ([]domain.BankTransaction, error) {
 	return m.transactions, nil
 }
 
+func (m *MockBankRepository) StreamTransactionsInRange(ctx context.Context, startDate, endDate time.Time) iter.Seq2[domain.BankTransaction, error] {
+	return seqOf(m.transactions)
+}
+
 func (m *MockBankRepository) GetBankIdentifier() string {
 	return m.BankID
 }
 
 func TestReconciliationService(t *testing.T) {
+	sysRepo, bankRepos := newTestRepositories(t)
+
+	// Create matcher with strategies
+	m := matcher.NewDefaultMatcher(
+		matcher.NewExactMatchStrategy(),
+		matcher.NewFuzzyMatchStrategy(0.10), // 0.10 threshold
+	)
+
+	// Create reconciliation service
+	service := service.NewReconciliationService(sysRepo, bankRepos, m, 1)
+
+	// Perform reconciliation
+	startDate := parseTime(t, "2025-01-15")
+	endDate := parseTime(t, "2025-01-20")
+	result, err := service.Reconcile(context.Background(), startDate, endDate)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Test number of matched transactions
+	expectedMatches := 4 // SYS-TXN-12345, SYS-TXN-12346, SYS-TXN-12347, SYS-TXN-12348
+	if len(result.MatchedTxns) != expectedMatches {
+		t.Errorf("Expected %d matches, got %d", expectedMatches, len(result.MatchedTxns))
+	}
+
+	// Test unmatched system transactions
+	expectedUnmatchedSys := 1 // SYS-TXN-12349
+	if len(result.UnMatchedSystemTxns) != expectedUnmatchedSys {
+		t.Errorf("Expected %d unmatched system transactions, got %d",
+			expectedUnmatchedSys, len(result.UnMatchedSystemTxns))
+	}
+
+	// Test unmatched bank transactions
+	expectedUnmatchedBankABC := 1 // BANK-STMT-98769
+	expectedUnmatchedBankBCD := 1 // BANK-STMT-88766
+
+	if len(result.UnMatchedBankTxns["Bank-ABC"]) != expectedUnmatchedBankABC {
+		t.Errorf("Expected %d unmatched Bank-ABC transactions, got %d",
+			expectedUnmatchedBankABC, len(result.UnMatchedBankTxns["Bank-ABC"]))
+	}
+
+	if len(result.UnMatchedBankTxns["Bank-BCD"]) != expectedUnmatchedBankBCD {
+		t.Errorf("Expected %d unmatched Bank-BCD transactions, got %d",
+			expectedUnmatchedBankBCD, len(result.UnMatchedBankTxns["Bank-BCD"]))
+	}
+
+	// Test total discrepancies
+	expectedDiscrepancy := decimal.NewFromFloat(0.05) // From SYS-TXN-12345 matching with BANK-STMT-98765
+	if !result.TotalDiscrepancies.Equal(expectedDiscrepancy) {
+		t.Errorf("Expected total discrepancies to be %s, got %s",
+			expectedDiscrepancy, result.TotalDiscrepancies)
+	}
+
+	// Test total transactions processed
+	expectedTotal := 11 // 2×4 matched rows + 1 unmatched system + 2 unmatched bank
+	if result.TotalTxnsProcessed != expectedTotal {
+		t.Errorf("Expected %d total transactions processed, got %d",
+			expectedTotal, result.TotalTxnsProcessed)
+	}
+}
+
+func TestReconciliationService_ReconcileStreaming(t *testing.T) {
+	sysRepo, bankRepos := newTestRepositories(t)
+
+	strategies := []matcher.MatchingStrategy{
+		matcher.NewExactMatchStrategy(),
+		matcher.NewFuzzyMatchStrategy(0.10),
+		matcher.NewDateBufferMatchStrategy(1),
+	}
+
+	startDate := parseTime(t, "2025-01-15")
+	endDate := parseTime(t, "2025-01-20")
+
+	expected, err := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewDefaultMatcher(strategies...), 1).
+		Reconcile(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewStreamMatcher(1, strategies...), 1).
+		ReconcileStreaming(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(result) != fmt.Sprint(expected) {
+		t.Errorf("Expected streaming result\n%v\nto equal\n%v", result, expected)
+	}
+
+	// The default matcher needs every transaction in memory, it can't match streams
+	_, err = service.NewReconciliationService(sysRepo, bankRepos, matcher.NewDefaultMatcher(strategies...), 1).
+		ReconcileStreaming(context.Background(), startDate, endDate)
+	if !errors.Is(err, service.ErrStreamingNotSupported) {
+		t.Errorf("Expected ErrStreamingNotSupported, got %v", err)
+	}
+}
+
+func TestReconciliationService_ReconcileStreamingAtPeriodBoundaries(t *testing.T) {
+	// Every pair straddles a boundary of the period, 2025-01-15 to 2025-01-20, by a day
+	sysRepo := &MockSystemRepository{
+		transactions: []domain.SystemTransaction{
+			{TrxID: "SYS-BEFORE", Amount: decimal.NewFromInt(100), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-14T10:00:00")},
+			{TrxID: "SYS-FIRST", Amount: decimal.NewFromInt(200), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-15T10:00:00")},
+			{TrxID: "SYS-LAST", Amount: decimal.NewFromInt(300), Type: domain.Debit, TransactionTime: parseTime(t, "2025-01-20T10:00:00")},
+			{TrxID: "SYS-AFTER", Amount: decimal.NewFromInt(400), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-21T10:00:00")},
+		},
+	}
+	bankRepos := map[string]domain.BankTransactionRepository{
+		"Bank-ABC": &MockBankRepository{
+			transactions: []domain.BankTransaction{
+				{UniqID: "BANK-FIRST", Amount: decimal.NewFromInt(200), Date: parseTime(t, "2025-01-14"), BankID: "Bank-ABC"},
+				{UniqID: "BANK-BEFORE", Amount: decimal.NewFromInt(100), Date: parseTime(t, "2025-01-15"), BankID: "Bank-ABC"},
+				{UniqID: "BANK-AFTER", Amount: decimal.NewFromInt(400), Date: parseTime(t, "2025-01-20"), BankID: "Bank-ABC"},
+				{UniqID: "BANK-LAST", Amount: decimal.NewFromInt(-300), Date: parseTime(t, "2025-01-21"), BankID: "Bank-ABC"},
+			},
+			BankID: "Bank-ABC",
+		},
+	}
+
+	strategies := []matcher.MatchingStrategy{
+		matcher.NewExactMatchStrategy(),
+		matcher.NewDateBufferMatchStrategy(1),
+	}
+
+	startDate := parseTime(t, "2025-01-15")
+	endDate := parseTime(t, "2025-01-20")
+
+	expected, err := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewDefaultMatcher(strategies...), 1).
+		Reconcile(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewStreamMatcher(1, strategies...), 1).
+		ReconcileStreaming(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The pairs with either side in the period are kept
+	if len(expected.MatchedTxns) != 4 || len(expected.UnMatchedBankTxns) != 0 {
+		t.Fatalf("Expected the 4 pairs matched, got %v and %v unmatched", expected.MatchedTxns, expected.UnMatchedBankTxns)
+	}
+
+	// The outcomes are reported in another order when streamed
+	for _, r := range []*domain.ReconciliationResult{&expected, &result} {
+		slices.SortFunc(r.MatchedTxns, func(a, b domain.Match) int { return strings.Compare(a.SystemTxn.TrxID, b.SystemTxn.TrxID) })
+		slices.SortFunc(r.UnMatchedBankTxns["Bank-ABC"], func(a, b domain.BankTransaction) int { return strings.Compare(a.UniqID, b.UniqID) })
+	}
+
+	if fmt.Sprint(result) != fmt.Sprint(expected) {
+		t.Errorf("Expected streaming result\n%v\nto equal\n%v", result, expected)
+	}
+}
+
+func TestReconciliationService_ReconcileStreamingSeveralBanks(t *testing.T) {
+	// SYS-1 can match A-1 or B-1, SYS-2 either of the bank transactions of its day
+	sysRepo := &MockSystemRepository{
+		transactions: []domain.SystemTransaction{
+			{TrxID: "SYS-1", Amount: decimal.NewFromInt(100), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-16T10:00:00")},
+			{TrxID: "SYS-2", Amount: decimal.NewFromInt(50), Type: domain.Debit, TransactionTime: parseTime(t, "2025-01-18T10:00:00")},
+		},
+	}
+	bankRepos := map[string]domain.BankTransactionRepository{
+		"Bank-A": &MockBankRepository{
+			transactions: []domain.BankTransaction{
+				{UniqID: "A-1", Amount: decimal.NewFromInt(100), Date: parseTime(t, "2025-01-17"), BankID: "Bank-A"},
+				{UniqID: "A-2", Amount: decimal.NewFromInt(-50), Date: parseTime(t, "2025-01-18"), BankID: "Bank-A"},
+			},
+			BankID: "Bank-A",
+		},
+		"Bank-B": &MockBankRepository{
+			transactions: []domain.BankTransaction{
+				{UniqID: "B-1", Amount: decimal.NewFromInt(100), Date: parseTime(t, "2025-01-15"), BankID: "Bank-B"},
+				{UniqID: "B-2", Amount: decimal.NewFromInt(-50), Date: parseTime(t, "2025-01-18"), BankID: "Bank-B"},
+			},
+			BankID: "Bank-B",
+		},
+	}
+
+	strategies := []matcher.MatchingStrategy{
+		matcher.NewExactMatchStrategy(),
+		matcher.NewDateBufferMatchStrategy(1),
+	}
+
+	startDate := parseTime(t, "2025-01-15")
+	endDate := parseTime(t, "2025-01-20")
+
+	expected, err := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewDefaultMatcher(strategies...), 1).
+		Reconcile(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewStreamMatcher(1, strategies...), 1).
+		ReconcileStreaming(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The first bank's transactions are the first candidates, whatever their day
+	if len(result.MatchedTxns) != 2 || result.MatchedTxns[0].BankTxn.UniqID != "A-1" || result.MatchedTxns[1].BankTxn.UniqID != "A-2" {
+		t.Errorf("Expected SYS-1 and SYS-2 to match A-1 and A-2, got %v", result.MatchedTxns)
+	}
+
+	if fmt.Sprint(result) != fmt.Sprint(expected) {
+		t.Errorf("Expected streaming result\n%v\nto equal\n%v", result, expected)
+	}
+}
+
+func TestReconciliationService_ReconcileToSinkWithTotals(t *testing.T) {
+	sysRepo, bankRepos := newTestRepositories(t)
+
+	strategies := []matcher.MatchingStrategy{
+		matcher.NewExactMatchStrategy(),
+		matcher.NewFuzzyMatchStrategy(0.10),
+		matcher.NewDateBufferMatchStrategy(1),
+	}
+
+	startDate := parseTime(t, "2025-01-15")
+	endDate := parseTime(t, "2025-01-20")
+
+	expected, err := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewDefaultMatcher(strategies...), 1).
+		Reconcile(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sink := &countingSink{}
+	summary, aging, err := service.NewReconciliationService(sysRepo, bankRepos, matcher.NewStreamMatcher(1, strategies...), 1).
+		ReconcileToSinkWithTotals(context.Background(), startDate, endDate, sink)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(summary) != fmt.Sprint(expected.Summary) {
+		t.Errorf("Expected summary\n%v\nto equal\n%v", summary, expected.Summary)
+	}
+	if fmt.Sprint(aging) != fmt.Sprint(expected.Aging) {
+		t.Errorf("Expected aging\n%v\nto equal\n%v", aging, expected.Aging)
+	}
+	if sink.outcomes != expected.TotalTxnsProcessed-len(expected.MatchedTxns) {
+		t.Errorf("Expected %d outcomes reported to the sink, got %d", expected.TotalTxnsProcessed-len(expected.MatchedTxns), sink.outcomes)
+	}
+}
+
+// countingSink counts the outcomes reported to it
+type countingSink struct {
+	outcomes int
+}
+
+func (c *countingSink) Matched(match domain.Match) error {
+	c.outcomes++
+	return nil
+}
+
+func (c *countingSink) UnmatchedSystem(txn domain.SystemTransaction) error {
+	c.outcomes++
+	return nil
+}
+
+func (c *countingSink) UnmatchedBank(txn domain.BankTransaction) error {
+	c.outcomes++
+	return nil
+}
+
+// newTestRepositories returns the mock repositories shared by the service tests
+func newTestRepositories(t *testing.T) (*MockSystemRepository, map[string]domain.BankTransactionRepository) {
 	// Create test data
 	sysRepo := &MockSystemRepository{
 		transactions: []domain.SystemTransaction{
@@ -129,63 +412,17 @@ func TestReconciliationService(t *testing.T) {
 		"Bank-BCD": bankRepoB,
 	}
 
-	// Create matcher with strategies
-	m := matcher.NewDefaultMatcher(
-		matcher.NewExactMatchStrategy(),
-		matcher.NewFuzzyMatchStrategy(0.10), // 0.10 threshold
-	)
-
-	// Create reconciliation service
-	service := service.NewReconciliationService(sysRepo, bankRepos, m, 1)
-
-	// Perform reconciliation
-	startDate := parseTime(t, "2025-01-15")
-	endDate := parseTime(t, "2025-01-20")
-	result, err := service.Reconcile(startDate, endDate)
-
-	if err != nil {
-		t.Fatalf("Unexpected error: %v", err)
-	}
-
-	// Test number of matched transactions
-	expectedMatches := 4 // SYS-TXN-12345, SYS-TXN-12346, SYS-TXN-12347, SYS-TXN-12348
-	if len(result.MatchedTxns) != expectedMatches {
-		t.Errorf("Expected %d matches, got %d", expectedMatches, len(result.MatchedTxns))
-	}
-
-	// Test unmatched system transactions
-	expectedUnmatchedSys := 1 // SYS-TXN-12349
-	if len(result.UnMatchedSystemTxns) != expectedUnmatchedSys {
-		t.Errorf("Expected %d unmatched system transactions, got %d",
-			expectedUnmatchedSys, len(result.UnMatchedSystemTxns))
-	}
-
-	// Test unmatched bank transactions
-	expectedUnmatchedBankABC := 1 // BANK-STMT-98769
-	expectedUnmatchedBankBCD := 1 // BANK-STMT-88766
-
-	if len(result.UnMatchedBankTxns["Bank-ABC"]) != expectedUnmatchedBankABC {
-		t.Errorf("Expected %d unmatched Bank-ABC transactions, got %d",
-			expectedUnmatchedBankABC, len(result.UnMatchedBankTxns["Bank-ABC"]))
-	}
-
-	if len(result.UnMatchedBankTxns["Bank-BCD"]) != expectedUnmatchedBankBCD {
-		t.Errorf("Expected %d unmatched Bank-BCD transactions, got %d",
-			expectedUnmatchedBankBCD, len(result.UnMatchedBankTxns["Bank-BCD"]))
-	}
-
-	// Test total discrepancies
-	expectedDiscrepancy := decimal.NewFromFloat(0.05) // From SYS-TXN-12345 matching with BANK-STMT-98765
-	if !result.TotalDiscrepancies.Equal(expectedDiscrepancy) {
-		t.Errorf("Expected total discrepancies to be %s, got %s",
-			expectedDiscrepancy, result.TotalDiscrepancies)
-	}
+	return sysRepo, bankRepos
+}
 
-	// Test total transactions processed
-	expectedTotal := 7 // 4 matched + 1 unmatched system + 2 unmatched bank
-	if result.TotalTxnsProcessed != expectedTotal {
-		t.Errorf("Expected %d total transactions processed, got %d",
-			expectedTotal, result.TotalTxnsProcessed)
+// seqOf yields the elements of a slice with a nil error
+func seqOf[T any](s []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, v := range s {
+			if !yield(v, nil) {
+				return
+			}
+		}
 	}
 }
 
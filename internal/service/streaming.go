@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+// ErrStreamingNotSupported is returned by the streaming methods when the matcher can't match streams
+var ErrStreamingNotSupported = errors.New("matcher doesn't support streaming")
+
+// ReconcileToSink reconciles date-sorted sources day by day, and reports every match and unmatched transaction
+// of the requested period to sink as soon as it is known. Memory use is bounded by the transactions of the
+// matching window rather than by the size of the inputs. The service's matcher must implement
+// domain.StreamingTransactionMatcher. Open items aren't carried forward, see ReconcileStreaming.
+func (s *ReconciliationService) ReconcileToSink(ctx context.Context, startDate, endDate time.Time, sink domain.MatchSink) error {
+	streamingMatcher, ok := s.matcher.(domain.StreamingTransactionMatcher)
+	if !ok {
+		return ErrStreamingNotSupported
+	}
+
+	// Calculate effective date range with buffer
+	effectiveStartDate := startDate.AddDate(0, 0, -s.dateBuffer)
+	effectiveEndDate := endDate.AddDate(0, 0, s.dateBuffer)
+
+	systemTxns := s.systemRepo.StreamTransactionsInRange(ctx, effectiveStartDate, effectiveEndDate)
+
+	// Sources are streamed in key order, the order Reconcile loads them in, so both find the same matches
+	var bankStreams []iter.Seq2[domain.BankTransaction, error]
+	for _, source := range slices.Sorted(maps.Keys(s.bankRepos)) {
+		bankStreams = append(bankStreams, s.bankRepos[source].StreamTransactionsInRange(ctx, effectiveStartDate, effectiveEndDate))
+	}
+
+	periodSink := &periodSink{sink: sink, startDate: startDate, endDate: endDate}
+	if err := streamingMatcher.MatchStreams(ctx, systemTxns, bankStreams, periodSink); err != nil {
+		return fmt.Errorf("matching transaction streams: %w", err)
+	}
+
+	return nil
+}
+
+// ReconcileStreaming performs the same reconciliation as Reconcile for date-sorted sources,
+// matching them day by day with ReconcileToSink instead of loading them in memory first
+func (s *ReconciliationService) ReconcileStreaming(ctx context.Context, startDate, endDate time.Time) (domain.ReconciliationResult, error) {
+	collector := &resultCollector{
+		unmatchedBankTxns: make(map[string][]domain.BankTransaction),
+	}
+
+	if err := s.ReconcileToSink(ctx, startDate, endDate, collector); err != nil {
+		return domain.ReconciliationResult{}, err
+	}
+
+	result := s.buildResult(collector.matches, collector.unmatchedSystemTxns, collector.unmatchedBankTxns)
+	if err := s.completeResult(ctx, startDate, endDate, &result); err != nil {
+		return domain.ReconciliationResult{}, err
+	}
+
+	return result, nil
+}
+
+// ReconcileToSinkWithTotals reconciles like ReconcileToSink, and returns the summary and the aging of the outcomes
+// reported to sink once they all are, for the writers that end with them without keeping the outcomes
+func (s *ReconciliationService) ReconcileToSinkWithTotals(ctx context.Context, startDate, endDate time.Time, sink domain.MatchSink) (domain.Summary, domain.Aging, error) {
+	summary, aging := newSummaryBuilder(), newAgingBuilder(endDate)
+	if err := s.ReconcileToSink(ctx, startDate, endDate, teeSink{sink, summary, aging}); err != nil {
+		return domain.Summary{}, domain.Aging{}, err
+	}
+	return summary.build(), aging.aging, nil
+}
+
+// periodSink forwards to sink only the outcomes within the requested period, not the buffered one
+type periodSink struct {
+	sink      domain.MatchSink
+	startDate time.Time
+	endDate   time.Time
+}
+
+// Matched forwards the matches with a transaction within the period, like Reconcile
+func (p *periodSink) Matched(match domain.Match) error {
+	if inPeriod(match.SystemTxn.TransactionTime, p.startDate, p.endDate) || inPeriod(match.BankTxn.Date, p.startDate, p.endDate) {
+		return p.sink.Matched(match)
+	}
+	return nil
+}
+
+func (p *periodSink) UnmatchedSystem(txn domain.SystemTransaction) error {
+	if !inPeriod(txn.TransactionTime, p.startDate, p.endDate) {
+		return nil
+	}
+	return p.sink.UnmatchedSystem(txn)
+}
+
+func (p *periodSink) UnmatchedBank(txn domain.BankTransaction) error {
+	if !inPeriod(txn.Date, p.startDate, p.endDate) {
+		return nil
+	}
+	return p.sink.UnmatchedBank(txn)
+}
+
+// resultCollector is a MatchSink gathering the outcomes into the parts of a ReconciliationResult
+type resultCollector struct {
+	matches             []domain.Match
+	unmatchedSystemTxns []domain.SystemTransaction
+	unmatchedBankTxns   map[string][]domain.BankTransaction
+}
+
+func (c *resultCollector) Matched(match domain.Match) error {
+	c.matches = append(c.matches, match)
+	return nil
+}
+
+func (c *resultCollector) UnmatchedSystem(txn domain.SystemTransaction) error {
+	c.unmatchedSystemTxns = append(c.unmatchedSystemTxns, txn)
+	return nil
+}
+
+func (c *resultCollector) UnmatchedBank(txn domain.BankTransaction) error {
+	c.unmatchedBankTxns[txn.BankID] = append(c.unmatchedBankTxns[txn.BankID], txn)
+	return nil
+}
+
+// teeSink reports every outcome to each of its sinks, stopping at the first error
+type teeSink []domain.MatchSink
+
+func (t teeSink) Matched(match domain.Match) error {
+	for _, sink := range t {
+		if err := sink.Matched(match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t teeSink) UnmatchedSystem(txn domain.SystemTransaction) error {
+	for _, sink := range t {
+		if err := sink.UnmatchedSystem(txn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t teeSink) UnmatchedBank(txn domain.BankTransaction) error {
+	for _, sink := range t {
+		if err := sink.UnmatchedBank(txn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inPeriod reports whether t falls on a day between startDate and endDate, both inclusive
+func inPeriod(t, startDate, endDate time.Time) bool {
+	day := t.Truncate(24 * time.Hour)
+	return !day.Before(startDate.Truncate(24*time.Hour)) && !day.After(endDate.Truncate(24*time.Hour))
+}
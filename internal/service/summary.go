@@ -0,0 +1,104 @@
+package service
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+// buildSummary totals the transactions of the period starting on startDate, matched, unmatched, or clearing
+// an open item of a past period
+func (s *ReconciliationService) buildSummary(result domain.ReconciliationResult, startDate time.Time) domain.Summary {
+	builder := newSummaryBuilder()
+
+	for _, match := range result.MatchedTxns {
+		builder.Matched(match)
+	}
+
+	for _, txn := range result.UnMatchedSystemTxns {
+		builder.UnmatchedSystem(txn)
+	}
+
+	for _, txns := range result.UnMatchedBankTxns {
+		for _, txn := range txns {
+			builder.UnmatchedBank(txn)
+		}
+	}
+
+	// Only the side of a cleared pair dated in the period is a transaction of the period, the other one is
+	// the open item. Open items matched by the date buffer are already counted with the matches
+	startDay := startDate.Truncate(24 * time.Hour)
+	for _, match := range clearingMatches(result) {
+		if match.SystemTxn.TransactionTime.Before(startDay) {
+			addTo(builder.summary.BankTxns, match.BankTxn.BankID, match.BankTxn.Amount)
+			builder.summary.ClearedOpenItems.Add(match.BankTxn.Amount)
+		} else {
+			builder.summary.SystemTxns.Add(signedAmount(match.SystemTxn))
+			builder.summary.ClearedOpenItems.Add(signedAmount(match.SystemTxn))
+		}
+	}
+
+	return builder.build()
+}
+
+// summaryBuilder is a MatchSink totaling the outcomes of a period as they're reported
+type summaryBuilder struct {
+	summary domain.Summary
+}
+
+func newSummaryBuilder() *summaryBuilder {
+	return &summaryBuilder{
+		summary: domain.Summary{
+			BankTxns:          make(map[string]domain.Total),
+			MatchedByStrategy: make(map[string]domain.Total),
+			UnmatchedBank:     make(map[string]domain.Total),
+		},
+	}
+}
+
+func (b *summaryBuilder) Matched(match domain.Match) error {
+	b.summary.SystemTxns.Add(signedAmount(match.SystemTxn))
+	addTo(b.summary.BankTxns, match.BankTxn.BankID, match.BankTxn.Amount)
+
+	b.summary.Matched.Add(match.BankTxn.Amount)
+	addTo(b.summary.MatchedByStrategy, match.Strategy, match.BankTxn.Amount)
+	return nil
+}
+
+func (b *summaryBuilder) UnmatchedSystem(txn domain.SystemTransaction) error {
+	b.summary.SystemTxns.Add(signedAmount(txn))
+	b.summary.UnmatchedSystem.Add(signedAmount(txn))
+	return nil
+}
+
+func (b *summaryBuilder) UnmatchedBank(txn domain.BankTransaction) error {
+	addTo(b.summary.BankTxns, txn.BankID, txn.Amount)
+	addTo(b.summary.UnmatchedBank, txn.BankID, txn.Amount)
+	return nil
+}
+
+// build returns the summary of the outcomes reported so far, with its match rate and net difference
+func (b *summaryBuilder) build() domain.Summary {
+	summary := b.summary
+
+	rows := summary.SystemTxns.Count
+	net := summary.SystemTxns.Amount
+	for _, total := range summary.BankTxns {
+		rows += total.Count
+		net = net.Sub(total.Amount)
+	}
+
+	if rows > 0 {
+		summary.MatchRate = float64(2*summary.Matched.Count+summary.ClearedOpenItems.Count) / float64(rows)
+	}
+	summary.NetUnexplainedDifference = net
+
+	return summary
+}
+
+func addTo(totals map[string]domain.Total, key string, amount decimal.Decimal) {
+	total := totals[key]
+	total.Add(amount)
+	totals[key] = total
+}
@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/matcher"
+	"github.com/tirasundara/reconciliation-service/internal/service"
+)
+
+func TestReconciliationService_Summary(t *testing.T) {
+	sysRepo := &MockSystemRepository{
+		transactions: []domain.SystemTransaction{
+			{TrxID: "SYS-1", Amount: decimal.NewFromInt(100), Type: domain.Credit, TransactionTime: parseTime(t, "2025-02-01T09:00:00")},
+			{TrxID: "SYS-2", Amount: decimal.NewFromInt(50), Type: domain.Debit, TransactionTime: parseTime(t, "2025-02-03T09:00:00")},
+			{TrxID: "SYS-3", Amount: decimal.NewFromInt(5), Type: domain.Debit, TransactionTime: parseTime(t, "2025-02-10T09:00:00")},
+		},
+	}
+
+	bankRepos := map[string]domain.BankTransactionRepository{
+		"Bank-ABC": &MockBankRepository{
+			BankID: "Bank-ABC",
+			transactions: []domain.BankTransaction{
+				{UniqID: "ABC-1", Amount: decimal.NewFromInt(100), Date: parseTime(t, "2025-02-01"), BankID: "Bank-ABC"},
+				{UniqID: "ABC-2", Amount: decimal.RequireFromString("-49.95"), Date: parseTime(t, "2025-02-03"), BankID: "Bank-ABC"},
+				{UniqID: "ABC-3", Amount: decimal.NewFromInt(42), Date: parseTime(t, "2025-02-05"), BankID: "Bank-ABC"},
+			},
+		},
+		"Bank-BCD": &MockBankRepository{
+			BankID: "Bank-BCD",
+			transactions: []domain.BankTransaction{
+				{UniqID: "BCD-1", Amount: decimal.NewFromInt(8), Date: parseTime(t, "2025-02-07"), BankID: "Bank-BCD"},
+			},
+		},
+	}
+
+	// ABC-3 clears a January item
+	openItems := &MockOpenItemRepository{
+		items: domain.OpenItems{
+			SystemTxns: []domain.SystemTransaction{
+				{TrxID: "SYS-JAN", Amount: decimal.NewFromInt(42), Type: domain.Credit, TransactionTime: parseTime(t, "2025-01-20T09:00:00")},
+			},
+		},
+	}
+
+	m := matcher.NewDefaultMatcher(matcher.NewExactMatchStrategy(), matcher.NewFuzzyMatchStrategy(0.1))
+	svc := service.NewReconciliationService(sysRepo, bankRepos, m, 1).WithOpenItems(openItems)
+
+	result, err := svc.Reconcile(context.Background(), parseTime(t, "2025-02-01"), parseTime(t, "2025-02-28"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	summary := result.Summary
+	tests := []struct {
+		name     string
+		got      domain.Total
+		expected domain.Total
+	}{
+		{"system", summary.SystemTxns, domain.Total{Count: 3, Amount: decimal.NewFromInt(45)}},
+		{"Bank-ABC", summary.BankTxns["Bank-ABC"], domain.Total{Count: 3, Amount: decimal.RequireFromString("92.05")}},
+		{"Bank-BCD", summary.BankTxns["Bank-BCD"], domain.Total{Count: 1, Amount: decimal.NewFromInt(8)}},
+		{"matched", summary.Matched, domain.Total{Count: 2, Amount: decimal.RequireFromString("50.05")}},
+		{"exact", summary.MatchedByStrategy["exact"], domain.Total{Count: 1, Amount: decimal.NewFromInt(100)}},
+		{"fuzzy", summary.MatchedByStrategy["fuzzy"], domain.Total{Count: 1, Amount: decimal.RequireFromString("-49.95")}},
+		{"cleared open items", summary.ClearedOpenItems, domain.Total{Count: 1, Amount: decimal.NewFromInt(42)}},
+		{"unmatched system", summary.UnmatchedSystem, domain.Total{Count: 1, Amount: decimal.NewFromInt(-5)}},
+		{"unmatched Bank-BCD", summary.UnmatchedBank["Bank-BCD"], domain.Total{Count: 1, Amount: decimal.NewFromInt(8)}},
+	}
+
+	for _, tt := range tests {
+		if tt.got.Count != tt.expected.Count || !tt.got.Amount.Equal(tt.expected.Amount) {
+			t.Errorf("%s: expected %d transactions for %s, got %d for %s",
+				tt.name, tt.expected.Count, tt.expected.Amount, tt.got.Count, tt.got.Amount)
+		}
+	}
+
+	if _, found := summary.UnmatchedBank["Bank-ABC"]; found {
+		t.Errorf("Expected no unmatched Bank-ABC transactions, got %+v", summary.UnmatchedBank["Bank-ABC"])
+	}
+
+	// 2 pairs and the transaction clearing the open item, out of 7
+	if expected := 5.0 / 7; summary.MatchRate != expected {
+		t.Errorf("Expected match rate %f, got %f", expected, summary.MatchRate)
+	}
+
+	// The unmatched transactions, the cleared open item and the 0.05 discrepancy of the fuzzy match
+	if expected := decimal.RequireFromString("-55.05"); !summary.NetUnexplainedDifference.Equal(expected) {
+		t.Errorf("Expected net unexplained difference %s, got %s", expected, summary.NetUnexplainedDifference)
+	}
+
+	if result.TotalTxnsProcessed != 7 {
+		t.Errorf("Expected 7 transactions processed, got %d", result.TotalTxnsProcessed)
+	}
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+// Open item dates are stored in UTC with a fixed width, so their text sorts in time order
+const openItemTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+const (
+	systemSide = "system"
+	bankSide   = "bank"
+)
+
+// GetOpenItems implements the domain.OpenItemRepository interface: it returns the items not cleared yet,
+// dated before the given date, oldest first
+func (s *Store) GetOpenItems(ctx context.Context, before time.Time) (domain.OpenItems, error) {
+	var items domain.OpenItems
+
+	err := s.queryOpenItems(ctx, before, func(side, id, bankID, amount, txnType, date string) error {
+		var t time.Time
+		if err := parseTime(date, &t); err != nil {
+			return err
+		}
+
+		switch side {
+		case systemSide:
+			txn := domain.SystemTransaction{TrxID: id, Type: domain.TransactionType(txnType), TransactionTime: t}
+			if err := parseDecimal(amount, &txn.Amount); err != nil {
+				return err
+			}
+			items.SystemTxns = append(items.SystemTxns, txn)
+
+		case bankSide:
+			txn := domain.BankTransaction{UniqID: id, BankID: bankID, Date: t}
+			if err := parseDecimal(amount, &txn.Amount); err != nil {
+				return err
+			}
+			items.BankTxns = append(items.BankTxns, txn)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.OpenItems{}, fmt.Errorf("reading open items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (s *Store) queryOpenItems(ctx context.Context, before time.Time, scan func(side, id, bankID, amount, txnType, date string) error) error {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT side, id, bank_id, amount, type, date FROM open_items WHERE cleared_on IS NULL AND date < ? ORDER BY date, side, key`,
+		formatOpenItemTime(before))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var side, id, bankID, amount, txnType, date string
+		if err := rows.Scan(&side, &id, &bankID, &amount, &txnType, &date); err != nil {
+			return err
+		}
+		if err := scan(side, id, bankID, amount, txnType, date); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// UpdateOpenItems implements the domain.OpenItemRepository interface. Items opened again, e.g. when a period
+// is reconciled twice, are updated and reopened
+func (s *Store) UpdateOpenItems(ctx context.Context, periodEnd time.Time, closed []domain.Match, opened domain.OpenItems) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("updating open items: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateOpenItems(ctx, tx, periodEnd, closed, opened); err != nil {
+		return fmt.Errorf("updating open items: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("updating open items: %w", err)
+	}
+
+	return nil
+}
+
+func updateOpenItems(ctx context.Context, tx *sql.Tx, periodEnd time.Time, closed []domain.Match, opened domain.OpenItems) error {
+	const upsert = `INSERT INTO open_items (side, key, id, bank_id, amount, type, date) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (side, key) DO UPDATE SET id = excluded.id, bank_id = excluded.bank_id, amount = excluded.amount,
+			type = excluded.type, date = excluded.date, cleared_on = NULL, cleared_by = ''`
+
+	for _, txn := range opened.SystemTxns {
+		if _, err := tx.ExecContext(ctx, upsert, systemSide, txn.TrxID, txn.TrxID, "",
+			txn.Amount.String(), string(txn.Type), formatOpenItemTime(txn.TransactionTime)); err != nil {
+			return err
+		}
+	}
+
+	for _, txn := range opened.BankTxns {
+		if _, err := tx.ExecContext(ctx, upsert, bankSide, bankItemKey(txn), txn.UniqID, txn.BankID,
+			txn.Amount.String(), "", formatOpenItemTime(txn.Date)); err != nil {
+			return err
+		}
+	}
+
+	const clearItem = `UPDATE open_items SET cleared_on = ?, cleared_by = ? WHERE side = ? AND key = ? AND cleared_on IS NULL`
+
+	clearedOn := periodEnd.Format(time.DateOnly)
+	for _, match := range closed {
+		if _, err := tx.ExecContext(ctx, clearItem, clearedOn, bankItemKey(match.BankTxn), systemSide, match.SystemTxn.TrxID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, clearItem, clearedOn, match.SystemTxn.TrxID, bankSide, bankItemKey(match.BankTxn)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func bankItemKey(txn domain.BankTransaction) string {
+	return fmt.Sprintf("%s-%s", txn.BankID, txn.UniqID)
+}
+
+func formatOpenItemTime(t time.Time) string {
+	return t.UTC().Format(openItemTimeLayout)
+}
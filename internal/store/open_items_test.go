@@ -0,0 +1,61 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+)
+
+func TestStore_OpenItems(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+
+	cheque := domain.SystemTransaction{TrxID: "SYS-CHQ", Amount: decimal.RequireFromString("500.10"), Type: domain.Debit, TransactionTime: day(1, 30).Add(10 * time.Hour)}
+	deposit := domain.BankTransaction{UniqID: "BNK-DEP", Amount: decimal.NewFromInt(70), Date: day(1, 29), BankID: "bank_a"}
+	february := domain.SystemTransaction{TrxID: "SYS-FEB", Amount: decimal.NewFromInt(5), Type: domain.Credit, TransactionTime: day(2, 10)}
+
+	// January leaves two items open, February one more
+	if err := s.UpdateOpenItems(ctx, day(1, 31), nil, domain.OpenItems{SystemTxns: []domain.SystemTransaction{cheque}, BankTxns: []domain.BankTransaction{deposit}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := s.UpdateOpenItems(ctx, day(2, 28), nil, domain.OpenItems{SystemTxns: []domain.SystemTransaction{february}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	items, err := s.GetOpenItems(ctx, day(2, 1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(items.SystemTxns) != 1 || len(items.BankTxns) != 1 {
+		t.Fatalf("Expected the 2 items of January, got %+v", items)
+	}
+
+	if got := items.SystemTxns[0]; got.TrxID != cheque.TrxID || !got.Amount.Equal(cheque.Amount) || got.Type != domain.Debit || !got.TransactionTime.Equal(cheque.TransactionTime) {
+		t.Errorf("Expected %+v, got %+v", cheque, got)
+	}
+
+	if got := items.BankTxns[0]; got.UniqID != deposit.UniqID || got.BankID != deposit.BankID || !got.Date.Equal(deposit.Date) {
+		t.Errorf("Expected %+v, got %+v", deposit, got)
+	}
+
+	// March clears the cheque
+	closed := []domain.Match{{SystemTxn: cheque, BankTxn: domain.BankTransaction{UniqID: "BNK-CHQ", BankID: "bank_a"}}}
+	if err := s.UpdateOpenItems(ctx, day(3, 31), closed, domain.OpenItems{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	items, err = s.GetOpenItems(ctx, day(3, 1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(items.SystemTxns) != 1 || items.SystemTxns[0].TrxID != "SYS-FEB" || len(items.BankTxns) != 1 {
+		t.Errorf("Expected SYS-FEB and BNK-DEP still open, got %+v", items)
+	}
+}
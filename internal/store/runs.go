@@ -0,0 +1,456 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+// ErrRunNotFound is returned for a run ID that isn't in the database
+var ErrRunNotFound = errors.New("run not found")
+
+// Run is a reconciliation run: what it was asked to do, what it read, and what it found
+type Run struct {
+	ID         int64
+	StartedAt  time.Time
+	FinishedAt time.Time
+	StartDate  time.Time
+	EndDate    time.Time
+	Params     map[string]string // Options of the run, e.g. the command-line flags
+	Inputs     []Input
+	Result     domain.ReconciliationResult
+	Rejects    []fileutil.Reject
+}
+
+// Input is a file read by a run, the hash tells whether a file changed since
+type Input struct {
+	Role   string // "system" or "bank"
+	Path   string
+	SHA256 string // Empty for streams, which can't be read twice
+	Size   int64
+}
+
+// RunSummary describes a run without its transactions
+type RunSummary struct {
+	ID                 int64
+	StartedAt          time.Time
+	FinishedAt         time.Time
+	StartDate          time.Time
+	EndDate            time.Time
+	Matched            int
+	UnmatchedSystem    int
+	UnmatchedBank      int
+	Rejects            int
+	TotalDiscrepancies decimal.Decimal
+}
+
+// HashInput returns the Input of the file at path, with its SHA-256. Stdin, "-", isn't hashed
+func HashInput(role, path string) (Input, error) {
+	input := Input{Role: role, Path: path}
+	if path == "-" {
+		return input, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Input{}, fmt.Errorf("hashing input file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return Input{}, fmt.Errorf("hashing input file: %w", err)
+	}
+
+	input.SHA256, input.Size = hex.EncodeToString(h.Sum(nil)), size
+	return input, nil
+}
+
+// SaveRun stores run and its results in a single transaction, and returns the ID given to it
+func (s *Store) SaveRun(ctx context.Context, run *Run) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("saving run: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, err := insertRun(ctx, tx, run)
+	if err != nil {
+		return 0, fmt.Errorf("saving run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("saving run: %w", err)
+	}
+
+	run.ID = id
+	return id, nil
+}
+
+func insertRun(ctx context.Context, tx *sql.Tx, run *Run) (int64, error) {
+	params, err := json.Marshal(run.Params)
+	if err != nil {
+		return 0, err
+	}
+
+	summary, err := json.Marshal(run.Result.Summary)
+	if err != nil {
+		return 0, err
+	}
+
+	aging, err := json.Marshal(run.Result.Aging)
+	if err != nil {
+		return 0, err
+	}
+
+	carriedForward, err := marshalReport(run.Result.CarriedForward)
+	if err != nil {
+		return 0, err
+	}
+
+	balances, err := marshalReport(run.Result.Balances)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO runs (started_at, finished_at, start_date, end_date, params, total_processed, total_discrepancies,
+			summary, aging, carried_forward, balances)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		formatTime(run.StartedAt), formatTime(run.FinishedAt), formatTime(run.StartDate), formatTime(run.EndDate),
+		string(params), run.Result.TotalTxnsProcessed, run.Result.TotalDiscrepancies.String(),
+		string(summary), string(aging), carriedForward, balances)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, input := range run.Inputs {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO run_inputs VALUES (?, ?, ?, ?, ?, ?)`,
+			id, i, input.Role, input.Path, input.SHA256, input.Size); err != nil {
+			return 0, err
+		}
+	}
+
+	for i, m := range run.Result.MatchedTxns {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO matches VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, i, m.SystemTxn.TrxID, m.SystemTxn.Amount.String(), string(m.SystemTxn.Type), formatTime(m.SystemTxn.TransactionTime),
+			m.BankTxn.BankID, m.BankTxn.UniqID, m.BankTxn.Amount.String(), formatTime(m.BankTxn.Date), m.AmmountDiff.String(),
+			m.Strategy); err != nil {
+			return 0, err
+		}
+	}
+
+	for i, txn := range run.Result.UnMatchedSystemTxns {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO unmatched_system VALUES (?, ?, ?, ?, ?, ?)`,
+			id, i, txn.TrxID, txn.Amount.String(), string(txn.Type), formatTime(txn.TransactionTime)); err != nil {
+			return 0, err
+		}
+	}
+
+	// Banks in key order, so a run reads back the same whatever the map iteration order
+	seq := 0
+	for _, bankID := range slices.Sorted(maps.Keys(run.Result.UnMatchedBankTxns)) {
+		for _, txn := range run.Result.UnMatchedBankTxns[bankID] {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO unmatched_bank VALUES (?, ?, ?, ?, ?, ?)`,
+				id, seq, bankID, txn.UniqID, txn.Amount.String(), formatTime(txn.Date)); err != nil {
+				return 0, err
+			}
+			seq++
+		}
+	}
+
+	for i, reject := range run.Rejects {
+		row, err := json.Marshal(reject.Row)
+		if err != nil {
+			return 0, err
+		}
+
+		var message string
+		if reject.Err != nil {
+			message = reject.Err.Error()
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO rejects VALUES (?, ?, ?, ?, ?, ?)`,
+			id, i, reject.File, reject.Line, string(row), message); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
+// marshalReport returns the JSON of an optional report, nil when it wasn't requested
+func marshalReport[T any](report *T) (any, error) {
+	if report == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// ListRuns returns the summary of every run, the latest first
+func (s *Store) ListRuns(ctx context.Context) ([]RunSummary, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.id, r.started_at, r.finished_at, r.start_date, r.end_date, r.total_discrepancies,
+			(SELECT COUNT(*) FROM matches WHERE run_id = r.id),
+			(SELECT COUNT(*) FROM unmatched_system WHERE run_id = r.id),
+			(SELECT COUNT(*) FROM unmatched_bank WHERE run_id = r.id),
+			(SELECT COUNT(*) FROM rejects WHERE run_id = r.id)
+		FROM runs r ORDER BY r.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("listing runs: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []RunSummary
+	for rows.Next() {
+		var summary RunSummary
+		var startedAt, finishedAt, startDate, endDate, discrepancies string
+		if err := rows.Scan(&summary.ID, &startedAt, &finishedAt, &startDate, &endDate, &discrepancies,
+			&summary.Matched, &summary.UnmatchedSystem, &summary.UnmatchedBank, &summary.Rejects); err != nil {
+			return nil, fmt.Errorf("listing runs: %w", err)
+		}
+
+		if err := parseFields(
+			parseTime(startedAt, &summary.StartedAt), parseTime(finishedAt, &summary.FinishedAt),
+			parseTime(startDate, &summary.StartDate), parseTime(endDate, &summary.EndDate),
+			parseDecimal(discrepancies, &summary.TotalDiscrepancies),
+		); err != nil {
+			return nil, fmt.Errorf("listing runs: run %d: %w", summary.ID, err)
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing runs: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// GetRun reads back the run with the given ID, with all its results
+func (s *Store) GetRun(ctx context.Context, id int64) (*Run, error) {
+	run := &Run{ID: id}
+
+	var startedAt, finishedAt, startDate, endDate, params, discrepancies, summary, aging string
+	var carriedForward, balances sql.NullString
+	err := s.db.QueryRowContext(ctx,
+		`SELECT started_at, finished_at, start_date, end_date, params, total_processed, total_discrepancies,
+			summary, aging, carried_forward, balances
+		FROM runs WHERE id = ?`, id,
+	).Scan(&startedAt, &finishedAt, &startDate, &endDate, &params, &run.Result.TotalTxnsProcessed, &discrepancies,
+		&summary, &aging, &carriedForward, &balances)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("reading run %d: %w", id, ErrRunNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading run %d: %w", id, err)
+	}
+
+	if carriedForward.Valid {
+		run.Result.CarriedForward = &domain.CarriedForward{}
+	}
+	if balances.Valid {
+		run.Result.Balances = &domain.BalanceReconciliation{}
+	}
+
+	if err := parseFields(
+		parseTime(startedAt, &run.StartedAt), parseTime(finishedAt, &run.FinishedAt),
+		parseTime(startDate, &run.StartDate), parseTime(endDate, &run.EndDate),
+		parseDecimal(discrepancies, &run.Result.TotalDiscrepancies),
+		json.Unmarshal([]byte(params), &run.Params),
+		json.Unmarshal([]byte(summary), &run.Result.Summary),
+		json.Unmarshal([]byte(aging), &run.Result.Aging),
+		unmarshalReport(carriedForward, run.Result.CarriedForward),
+		unmarshalReport(balances, run.Result.Balances),
+	); err != nil {
+		return nil, fmt.Errorf("reading run %d: %w", id, err)
+	}
+
+	for _, read := range []func(context.Context, *Run) error{s.readInputs, s.readMatches, s.readUnmatched, s.readRejects} {
+		if err := read(ctx, run); err != nil {
+			return nil, fmt.Errorf("reading run %d: %w", id, err)
+		}
+	}
+
+	return run, nil
+}
+
+// unmarshalReport reads the JSON of an optional report into report, when it was recorded
+func unmarshalReport(data sql.NullString, report any) error {
+	if !data.Valid {
+		return nil
+	}
+	return json.Unmarshal([]byte(data.String), report)
+}
+
+func (s *Store) readInputs(ctx context.Context, run *Run) error {
+	return s.query(ctx, `SELECT role, path, sha256, size FROM run_inputs WHERE run_id = ? ORDER BY seq`, run.ID,
+		func(rows *sql.Rows) error {
+			var input Input
+			if err := rows.Scan(&input.Role, &input.Path, &input.SHA256, &input.Size); err != nil {
+				return err
+			}
+			run.Inputs = append(run.Inputs, input)
+			return nil
+		})
+}
+
+func (s *Store) readMatches(ctx context.Context, run *Run) error {
+	return s.query(ctx, `SELECT trx_id, system_amount, type, transaction_time, bank_id, uniq_id, bank_amount, date,
+			amount_diff, strategy
+		FROM matches WHERE run_id = ? ORDER BY seq`, run.ID,
+		func(rows *sql.Rows) error {
+			var m domain.Match
+			var sysAmount, txnType, txnTime, bankAmount, date, diff string
+			if err := rows.Scan(&m.SystemTxn.TrxID, &sysAmount, &txnType, &txnTime,
+				&m.BankTxn.BankID, &m.BankTxn.UniqID, &bankAmount, &date, &diff, &m.Strategy); err != nil {
+				return err
+			}
+
+			m.SystemTxn.Type = domain.TransactionType(txnType)
+			if err := parseFields(
+				parseDecimal(sysAmount, &m.SystemTxn.Amount), parseTime(txnTime, &m.SystemTxn.TransactionTime),
+				parseDecimal(bankAmount, &m.BankTxn.Amount), parseTime(date, &m.BankTxn.Date),
+				parseDecimal(diff, &m.AmmountDiff),
+			); err != nil {
+				return err
+			}
+
+			run.Result.MatchedTxns = append(run.Result.MatchedTxns, m)
+			return nil
+		})
+}
+
+func (s *Store) readUnmatched(ctx context.Context, run *Run) error {
+	err := s.query(ctx, `SELECT trx_id, amount, type, transaction_time FROM unmatched_system WHERE run_id = ? ORDER BY seq`, run.ID,
+		func(rows *sql.Rows) error {
+			var txn domain.SystemTransaction
+			var amount, txnType, txnTime string
+			if err := rows.Scan(&txn.TrxID, &amount, &txnType, &txnTime); err != nil {
+				return err
+			}
+
+			txn.Type = domain.TransactionType(txnType)
+			if err := parseFields(parseDecimal(amount, &txn.Amount), parseTime(txnTime, &txn.TransactionTime)); err != nil {
+				return err
+			}
+
+			run.Result.UnMatchedSystemTxns = append(run.Result.UnMatchedSystemTxns, txn)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	run.Result.UnMatchedBankTxns = make(map[string][]domain.BankTransaction)
+	return s.query(ctx, `SELECT bank_id, uniq_id, amount, date FROM unmatched_bank WHERE run_id = ? ORDER BY seq`, run.ID,
+		func(rows *sql.Rows) error {
+			var txn domain.BankTransaction
+			var amount, date string
+			if err := rows.Scan(&txn.BankID, &txn.UniqID, &amount, &date); err != nil {
+				return err
+			}
+
+			if err := parseFields(parseDecimal(amount, &txn.Amount), parseTime(date, &txn.Date)); err != nil {
+				return err
+			}
+
+			run.Result.UnMatchedBankTxns[txn.BankID] = append(run.Result.UnMatchedBankTxns[txn.BankID], txn)
+			return nil
+		})
+}
+
+func (s *Store) readRejects(ctx context.Context, run *Run) error {
+	return s.query(ctx, `SELECT file, line, row, error FROM rejects WHERE run_id = ? ORDER BY seq`, run.ID,
+		func(rows *sql.Rows) error {
+			var reject fileutil.Reject
+			var row, message string
+			if err := rows.Scan(&reject.File, &reject.Line, &row, &message); err != nil {
+				return err
+			}
+
+			if err := json.Unmarshal([]byte(row), &reject.Row); err != nil {
+				return err
+			}
+			reject.Err = errors.New(message)
+
+			run.Rejects = append(run.Rejects, reject)
+			return nil
+		})
+}
+
+// query runs a query with a single argument and calls scan for each row
+func (s *Store) query(ctx context.Context, query string, arg any, scan func(*sql.Rows) error) error {
+	rows, err := s.db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Times are stored as RFC 3339 text, which keeps their offset and sorts in time order for a given offset
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(s string, t *time.Time) error {
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// Amounts are stored as text, REAL columns would lose the exact decimal value
+func parseDecimal(s string, d *decimal.Decimal) error {
+	parsed, err := decimal.NewFromString(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// parseFields returns the first error of parsing several fields
+func parseFields(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/tirasundara/reconciliation-service/internal/domain"
+	"github.com/tirasundara/reconciliation-service/internal/store"
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+func TestStore_SaveAndGetRun(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	run := newTestRun()
+	id, err := s.SaveRun(ctx, run)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, err := s.GetRun(ctx, id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !got.StartedAt.Equal(run.StartedAt) || !got.EndDate.Equal(run.EndDate) {
+		t.Errorf("Expected run from %s to %s, got %s to %s", run.StartedAt, run.EndDate, got.StartedAt, got.EndDate)
+	}
+
+	if !reflect.DeepEqual(got.Params, run.Params) || !reflect.DeepEqual(got.Inputs, run.Inputs) {
+		t.Errorf("Expected params %v and inputs %v, got %v and %v", run.Params, run.Inputs, got.Params, got.Inputs)
+	}
+
+	if len(got.Result.MatchedTxns) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(got.Result.MatchedTxns))
+	}
+
+	match := got.Result.MatchedTxns[0]
+	if match.SystemTxn.TrxID != "SYS-1" || match.BankTxn.UniqID != "BNK-1" || !match.AmmountDiff.Equal(decimal.RequireFromString("0.05")) {
+		t.Errorf("Expected SYS-1 matched with BNK-1 with a 0.05 difference, got %+v", match)
+	}
+
+	if match.Strategy != "fuzzy" {
+		t.Errorf("Expected the fuzzy strategy, got %q", match.Strategy)
+	}
+
+	if !match.SystemTxn.TransactionTime.Equal(run.Result.MatchedTxns[0].SystemTxn.TransactionTime) {
+		t.Errorf("Expected transaction time %s, got %s", run.Result.MatchedTxns[0].SystemTxn.TransactionTime, match.SystemTxn.TransactionTime)
+	}
+
+	if len(got.Result.UnMatchedSystemTxns) != 1 || got.Result.UnMatchedSystemTxns[0].Type != domain.Credit {
+		t.Errorf("Expected 1 unmatched credit, got %+v", got.Result.UnMatchedSystemTxns)
+	}
+
+	if len(got.Result.UnMatchedBankTxns["bank_a"]) != 2 || len(got.Result.UnMatchedBankTxns["bank_b"]) != 1 {
+		t.Errorf("Expected 2 unmatched bank_a and 1 bank_b transactions, got %+v", got.Result.UnMatchedBankTxns)
+	}
+
+	if !got.Result.TotalDiscrepancies.Equal(run.Result.TotalDiscrepancies) || got.Result.TotalTxnsProcessed != 5 {
+		t.Errorf("Expected 5 transactions and %s discrepancies, got %d and %s",
+			run.Result.TotalDiscrepancies, got.Result.TotalTxnsProcessed, got.Result.TotalDiscrepancies)
+	}
+
+	if got.Result.Summary.MatchedByStrategy["fuzzy"].Count != 1 || !got.Result.Summary.NetUnexplainedDifference.Equal(decimal.RequireFromString("6.39")) {
+		t.Errorf("Expected the summary of the run, got %+v", got.Result.Summary)
+	}
+
+	if len(got.Result.Aging.System) != 1 || got.Result.Aging.System[0].Count != 1 || !got.Result.Aging.Bank["bank_a"][0].Amount.Equal(decimal.RequireFromString("7")) {
+		t.Errorf("Expected the aging of the run, got %+v", got.Result.Aging)
+	}
+
+	if got.Result.CarriedForward == nil || len(got.Result.CarriedForward.OpenBankTxns) != 1 || got.Result.CarriedForward.OpenBankTxns[0].AgeDays != 40 {
+		t.Errorf("Expected BNK-OLD open for 40 days, got %+v", got.Result.CarriedForward)
+	}
+
+	if got.Result.Balances == nil || got.Result.Balances.Statement == nil || !got.Result.Balances.Statement.Difference.Equal(decimal.RequireFromString("0.05")) {
+		t.Errorf("Expected the bank to book statement of the run, got %+v", got.Result.Balances)
+	}
+
+	if len(got.Rejects) != 1 || got.Rejects[0].Line != 7 || got.Rejects[0].Err.Error() != "invalid amount format" {
+		t.Errorf("Expected the reject on line 7, got %+v", got.Rejects)
+	}
+}
+
+func TestStore_ListRuns(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	for range 2 {
+		if _, err := s.SaveRun(ctx, newTestRun()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	runs, err := s.ListRuns(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(runs) != 2 || runs[0].ID != 2 {
+		t.Fatalf("Expected 2 runs, the latest first, got %+v", runs)
+	}
+
+	if runs[0].Matched != 1 || runs[0].UnmatchedSystem != 1 || runs[0].UnmatchedBank != 3 || runs[0].Rejects != 1 {
+		t.Errorf("Expected 1 match, 1 unmatched system, 3 unmatched bank transactions and 1 reject, got %+v", runs[0])
+	}
+}
+
+func TestStore_GetRunWithoutReports(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	// The carried forward and balances reports are only there when they were requested
+	run := newTestRun()
+	run.Result.CarriedForward, run.Result.Balances = nil, nil
+
+	id, err := s.SaveRun(ctx, run)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, err := s.GetRun(ctx, id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got.Result.CarriedForward != nil || got.Result.Balances != nil {
+		t.Errorf("Expected no carried forward and balances reports, got %+v and %+v", got.Result.CarriedForward, got.Result.Balances)
+	}
+	if got.Result.Summary.MatchedByStrategy["fuzzy"].Count != 1 {
+		t.Errorf("Expected the summary of the run, got %+v", got.Result.Summary)
+	}
+}
+
+func TestStore_GetRunNotFound(t *testing.T) {
+	_, err := openTestStore(t).GetRun(context.Background(), 42)
+	if !errors.Is(err, store.ErrRunNotFound) {
+		t.Errorf("Expected ErrRunNotFound, got %v", err)
+	}
+}
+
+func TestHashInput(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "input.csv")
+	if err := os.WriteFile(fp, []byte("abc"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	input, err := store.HashInput("bank", fp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if input.SHA256 != expected || input.Size != 3 {
+		t.Errorf("Expected hash %s of 3 bytes, got %s of %d", expected, input.SHA256, input.Size)
+	}
+}
+
+func openTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	s, err := store.Open(context.Background(), filepath.Join(t.TempDir(), "runs.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func newTestRun() *store.Run {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	return &store.Run{
+		StartedAt:  time.Date(2025, 2, 1, 9, 0, 0, 123, jakarta),
+		FinishedAt: time.Date(2025, 2, 1, 9, 0, 2, 0, jakarta),
+		StartDate:  day(1),
+		EndDate:    day(31).Add(24*time.Hour - time.Second),
+		Params:     map[string]string{"date-buffer": "1", "amount-threshold": "0.1"},
+		Inputs: []store.Input{
+			{Role: "system", Path: "system.csv", SHA256: "abc", Size: 10},
+			{Role: "bank", Path: "-"},
+		},
+		Result: domain.ReconciliationResult{
+			TotalTxnsProcessed: 5,
+			MatchedTxns: []domain.Match{{
+				SystemTxn:   domain.SystemTransaction{TrxID: "SYS-1", Amount: decimal.RequireFromString("100.05"), Type: domain.Debit, TransactionTime: day(3).Add(90 * time.Minute)},
+				BankTxn:     domain.BankTransaction{UniqID: "BNK-1", Amount: decimal.RequireFromString("-100"), Date: day(3), BankID: "bank_a"},
+				AmmountDiff: decimal.RequireFromString("0.05"),
+				Strategy:    "fuzzy",
+			}},
+			UnMatchedSystemTxns: []domain.SystemTransaction{
+				{TrxID: "SYS-2", Amount: decimal.RequireFromString("12.34"), Type: domain.Credit, TransactionTime: day(4)},
+			},
+			UnMatchedBankTxns: map[string][]domain.BankTransaction{
+				"bank_b": {{UniqID: "BNK-9", Amount: decimal.RequireFromString("1"), Date: day(9), BankID: "bank_b"}},
+				"bank_a": {
+					{UniqID: "BNK-2", Amount: decimal.RequireFromString("2"), Date: day(5), BankID: "bank_a"},
+					{UniqID: "BNK-3", Amount: decimal.RequireFromString("3"), Date: day(6), BankID: "bank_a"},
+				},
+			},
+			TotalDiscrepancies: decimal.RequireFromString("0.05"),
+			Summary: domain.Summary{
+				Matched:                  domain.Total{Count: 1, Amount: decimal.RequireFromString("-100")},
+				MatchedByStrategy:        map[string]domain.Total{"fuzzy": {Count: 1, Amount: decimal.RequireFromString("-100")}},
+				MatchRate:                0.4,
+				NetUnexplainedDifference: decimal.RequireFromString("6.39"),
+			},
+			Aging: domain.Aging{
+				System: domain.AgingBuckets{{Bucket: "8-30", Count: 1, Amount: decimal.RequireFromString("12.34")}},
+				Bank:   map[string]domain.AgingBuckets{"bank_a": {{Bucket: ">30", Count: 1, Amount: decimal.RequireFromString("7")}}},
+			},
+			CarriedForward: &domain.CarriedForward{
+				OpenBankTxns: []domain.AgedBankTransaction{{
+					BankTransaction: domain.BankTransaction{UniqID: "BNK-OLD", Amount: decimal.RequireFromString("7"), Date: day(1).AddDate(0, 0, -9), BankID: "bank_a"},
+					AgeDays:         40,
+				}},
+			},
+			Balances: &domain.BalanceReconciliation{
+				Bank: map[string]domain.BalanceCheck{"bank_a": {Opening: decimal.RequireFromString("10"), Closing: decimal.RequireFromString("10"), Balanced: true}},
+				Statement: &domain.BankToBookStatement{
+					BankBalance: decimal.RequireFromString("10"),
+					Difference:  decimal.RequireFromString("0.05"),
+				},
+			},
+		},
+		Rejects: []fileutil.Reject{
+			{File: "system.csv", Line: 7, Row: []string{"SYS-X", "oops"}, Err: errors.New("invalid amount format")},
+		},
+	}
+}
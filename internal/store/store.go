@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// schema creates the tables of a new database, it runs on every Open. The summary, aging, carried forward and
+// balances reports of a run are only read back whole, they're kept as JSON
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at          TEXT NOT NULL,
+	finished_at         TEXT NOT NULL,
+	start_date          TEXT NOT NULL,
+	end_date            TEXT NOT NULL,
+	params              TEXT NOT NULL,
+	total_processed     INTEGER NOT NULL,
+	total_discrepancies TEXT NOT NULL,
+	summary             TEXT NOT NULL,
+	aging               TEXT NOT NULL,
+	carried_forward     TEXT,
+	balances            TEXT
+);
+
+CREATE TABLE IF NOT EXISTS run_inputs (
+	run_id INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	seq    INTEGER NOT NULL,
+	role   TEXT NOT NULL,
+	path   TEXT NOT NULL,
+	sha256 TEXT NOT NULL,
+	size   INTEGER NOT NULL,
+	PRIMARY KEY (run_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS matches (
+	run_id           INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	seq              INTEGER NOT NULL,
+	trx_id           TEXT NOT NULL,
+	system_amount    TEXT NOT NULL,
+	type             TEXT NOT NULL,
+	transaction_time TEXT NOT NULL,
+	bank_id          TEXT NOT NULL,
+	uniq_id          TEXT NOT NULL,
+	bank_amount      TEXT NOT NULL,
+	date             TEXT NOT NULL,
+	amount_diff      TEXT NOT NULL,
+	strategy         TEXT NOT NULL,
+	PRIMARY KEY (run_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS unmatched_system (
+	run_id           INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	seq              INTEGER NOT NULL,
+	trx_id           TEXT NOT NULL,
+	amount           TEXT NOT NULL,
+	type             TEXT NOT NULL,
+	transaction_time TEXT NOT NULL,
+	PRIMARY KEY (run_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS unmatched_bank (
+	run_id  INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	seq     INTEGER NOT NULL,
+	bank_id TEXT NOT NULL,
+	uniq_id TEXT NOT NULL,
+	amount  TEXT NOT NULL,
+	date    TEXT NOT NULL,
+	PRIMARY KEY (run_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS rejects (
+	run_id INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	seq    INTEGER NOT NULL,
+	file   TEXT NOT NULL,
+	line   INTEGER NOT NULL,
+	row    TEXT NOT NULL,
+	error  TEXT NOT NULL,
+	PRIMARY KEY (run_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS open_items (
+	side       TEXT NOT NULL,
+	key        TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	bank_id    TEXT NOT NULL,
+	amount     TEXT NOT NULL,
+	type       TEXT NOT NULL,
+	date       TEXT NOT NULL,
+	cleared_on TEXT,
+	cleared_by TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (side, key)
+);
+`
+
+// Store keeps the history of reconciliation runs, and the open items carried forward from one period to the
+// next, in an embedded SQLite database
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the SQLite database at path, creating it and its tables when needed
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("opening run database: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating run database tables: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,203 @@
+package fileutil
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrCompressed is returned when seeking within a compressed file, e.g. to index it
+var ErrCompressed = errors.New("compressed files can't be read by byte range")
+
+// Compression is the compression format of a file, detected from its first bytes
+type Compression int
+
+const (
+	Uncompressed Compression = iota
+	Gzip
+	Bzip2
+	Zip
+)
+
+var magicBytes = []struct {
+	compression Compression
+	magic       []byte
+}{
+	{Gzip, []byte{0x1f, 0x8b}},
+	{Bzip2, []byte("BZh")},
+	{Zip, []byte("PK\x03\x04")},
+	{Zip, []byte("PK\x05\x06")}, // Empty archive
+}
+
+func (c Compression) String() string {
+	switch c {
+	case Gzip:
+		return "gzip"
+	case Bzip2:
+		return "bzip2"
+	case Zip:
+		return "zip"
+	default:
+		return "uncompressed"
+	}
+}
+
+// DetectCompression reads the magic bytes of a file to tell how it's compressed
+func DetectCompression(filePath string) (Compression, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return Uncompressed, fmt.Errorf("opening a csv file: %w", err)
+	}
+	defer f.Close()
+
+	return detectCompression(f)
+}
+
+func detectCompression(r io.ReaderAt) (Compression, error) {
+	head := make([]byte, 4)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return Uncompressed, fmt.Errorf("reading file header: %w", err)
+	}
+
+	return compressionOf(head[:n]), nil
+}
+
+// compressionOf tells the compression format from the first bytes of a file
+func compressionOf(head []byte) Compression {
+	for _, m := range magicBytes {
+		if bytes.HasPrefix(head, m.magic) {
+			return m.compression
+		}
+	}
+
+	return Uncompressed
+}
+
+// TrimExtensions returns the base name of a file without its CSV and compression extensions,
+// e.g. "bank_abc" for "statements/bank_abc.csv.gz"
+func TrimExtensions(filePath string) string {
+	name := filepath.Base(filePath)
+
+	for _, ext := range []string{".gz", ".bz2", ".zip", ".csv", ".xlsx"} {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			name = name[:len(name)-len(ext)]
+		}
+	}
+
+	return name
+}
+
+// zipEntries lists the CSV entries of a zip archive, in archive order
+func zipEntries(filePath string) ([]string, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening zip archive: %w", err)
+	}
+	defer archive.Close()
+
+	var entries []string
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || !strings.EqualFold(path.Ext(entry.Name), ".csv") {
+			continue
+		}
+		entries = append(entries, entry.Name)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("zip archive %s holds no csv file", filePath)
+	}
+
+	return entries, nil
+}
+
+// decompressedFile is the decompressed content of a file, closing it closes the file too
+type decompressedFile struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d *decompressedFile) Close() error {
+	var errs []error
+	for i := len(d.closers) - 1; i >= 0; i-- {
+		errs = append(errs, d.closers[i].Close())
+	}
+	return errors.Join(errs...)
+}
+
+// decompress opens the content of f, or of its entry when f is a zip archive
+func decompress(f *os.File, entry string) (*decompressedFile, Compression, error) {
+	compression, err := detectCompression(f)
+	if err != nil {
+		return nil, Uncompressed, err
+	}
+
+	switch compression {
+	case Gzip:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, compression, fmt.Errorf("reading gzip file: %w", err)
+		}
+		return &decompressedFile{Reader: gz, closers: []io.Closer{f, gz}}, compression, nil
+
+	case Bzip2:
+		return &decompressedFile{Reader: bzip2.NewReader(f), closers: []io.Closer{f}}, compression, nil
+
+	case Zip:
+		stat, err := f.Stat()
+		if err != nil {
+			return nil, compression, fmt.Errorf("reading zip archive info: %w", err)
+		}
+
+		archive, err := zip.NewReader(f, stat.Size())
+		if err != nil {
+			return nil, compression, fmt.Errorf("opening zip archive: %w", err)
+		}
+
+		if entry == "" {
+			return nil, compression, fmt.Errorf("reading zip archive %s: no entry selected", f.Name())
+		}
+
+		rc, err := archive.Open(entry)
+		if err != nil {
+			return nil, compression, fmt.Errorf("opening zip entry: %w", err)
+		}
+		return &decompressedFile{Reader: rc, closers: []io.Closer{f, rc}}, compression, nil
+
+	default:
+		return &decompressedFile{Reader: f, closers: []io.Closer{f}}, compression, nil
+	}
+}
+
+// decompressStream decompresses a stream that can't be seeked, e.g. stdin, so zip archives aren't supported
+func decompressStream(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(4) // A shorter stream is simply not compressed
+
+	switch compressionOf(head) {
+	case Gzip:
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading gzip stream: %w", err)
+		}
+		return gz, nil
+
+	case Bzip2:
+		return bzip2.NewReader(br), nil
+
+	case Zip:
+		return nil, errors.New("zip archives can only be read from a file")
+
+	default:
+		return br, nil
+	}
+}
@@ -0,0 +1,187 @@
+package fileutil_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+func TestLoader_CompressedInputs(t *testing.T) {
+	lines := []string{"id,value"}
+	for i := 0; i < 250; i++ {
+		lines = append(lines, fmt.Sprintf("ROW-%03d,%d", i, i))
+	}
+	content := strings.Join(lines, "\n") + "\n"
+
+	dir := t.TempDir()
+
+	var gz bytes.Buffer
+	gzw := gzip.NewWriter(&gz)
+	gzw.Write([]byte(content))
+	gzw.Close()
+	gzPath := filepath.Join(dir, "rows.csv.gz")
+	writeFile(t, gzPath, gz.Bytes())
+
+	// The rows split over two entries, with an entry that isn't CSV in between
+	zipPath := filepath.Join(dir, "rows.zip")
+	writeZip(t, zipPath, map[string]string{
+		"part1.csv":  strings.Join(lines[:101], "\n") + "\n",
+		"README.txt": "not a csv file",
+		"part2.csv":  "id,value\n" + strings.Join(lines[101:], "\n") + "\n",
+	}, []string{"part1.csv", "README.txt", "part2.csv"})
+
+	loader := newTestLoader()
+	loader.BatchSize = 16
+
+	expected, err := loader.Load(context.Background(), fileutil.NewCSVReader(writeTestCSV(t, lines)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, fp := range []string{gzPath, zipPath} {
+		for _, concurrent := range []bool{false, true} {
+			load := loader.Load
+			if concurrent {
+				load = loader.LoadConcurrently
+			}
+
+			rows, err := load(context.Background(), fileutil.NewCSVReader(fp))
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", filepath.Base(fp), err)
+			}
+
+			if !slices.Equal(rows, expected) {
+				t.Errorf("%s (concurrent: %v): expected the %d rows of the plain file, got %d",
+					filepath.Base(fp), concurrent, len(expected), len(rows))
+			}
+		}
+	}
+}
+
+func TestLoader_Bzip2Input(t *testing.T) {
+	loader := &fileutil.Loader[[]string]{
+		Decoder: func([]string) (fileutil.RowDecoder[[]string], error) {
+			return func(row []string) ([]string, error) { return row, nil }, nil
+		},
+	}
+
+	rows, err := loader.LoadConcurrently(context.Background(), fileutil.NewCSVReader("../../test/testdata/compressed/bank_statements.csv.bz2"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(rows) != 6 {
+		t.Errorf("Expected 6 rows, got %d", len(rows))
+	}
+}
+
+func TestLoader_ZipRejectsNameTheirEntry(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "rows.zip")
+	writeZip(t, zipPath, map[string]string{
+		"a.csv": "id,value\nA-1,1\n",
+		"b.csv": "id,value\nB-1,1\nB-2,oops\n",
+	}, []string{"a.csv", "b.csv"})
+
+	var rejects []fileutil.Reject
+	loader := newTestLoader()
+	loader.Reject = func(reject fileutil.Reject) { rejects = append(rejects, reject) }
+
+	if _, err := loader.Load(context.Background(), fileutil.NewCSVReader(zipPath)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(rejects) != 1 {
+		t.Fatalf("Expected 1 reject, got %d", len(rejects))
+	}
+
+	if rejects[0].File != zipPath+":b.csv" || rejects[0].Line != 3 {
+		t.Errorf("Expected the reject at %s:b.csv line 3, got %s line %d", zipPath, rejects[0].File, rejects[0].Line)
+	}
+}
+
+func TestDetectCompression(t *testing.T) {
+	tests := []struct {
+		content  []byte
+		expected fileutil.Compression
+	}{
+		{[]byte("id,value\n"), fileutil.Uncompressed},
+		{[]byte{}, fileutil.Uncompressed},
+		{[]byte{0x1f, 0x8b, 0x08, 0x00}, fileutil.Gzip},
+		{[]byte("BZh91AY"), fileutil.Bzip2},
+		{[]byte("PK\x03\x04rest"), fileutil.Zip},
+	}
+
+	for _, tt := range tests {
+		fp := filepath.Join(t.TempDir(), "file")
+		writeFile(t, fp, tt.content)
+
+		compression, err := fileutil.DetectCompression(fp)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if compression != tt.expected {
+			t.Errorf("Expected %s for %q, got %s", tt.expected, tt.content, compression)
+		}
+	}
+}
+
+func TestBuildDayIndex_Compressed(t *testing.T) {
+	_, err := fileutil.BuildDayIndex("../../test/testdata/compressed/bank_statements.csv.bz2", "date", "2006-01-02")
+	if !errors.Is(err, fileutil.ErrCompressed) {
+		t.Errorf("Expected ErrCompressed, got %v", err)
+	}
+}
+
+func TestTrimExtensions(t *testing.T) {
+	tests := map[string]string{
+		"bank_abc.csv":                "bank_abc",
+		"statements/bank_abc.csv.gz":  "bank_abc",
+		"statements/bank_abc.CSV.bz2": "bank_abc",
+		"bank_abc.zip":                "bank_abc",
+	}
+
+	for fp, expected := range tests {
+		if got := fileutil.TrimExtensions(fp); got != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, fp, got)
+		}
+	}
+}
+
+func writeFile(t *testing.T, fp string, content []byte) {
+	t.Helper()
+
+	if err := os.WriteFile(fp, content, 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+}
+
+// writeZip writes a zip archive with the given entries, in order
+func writeZip(t *testing.T, fp string, entries map[string]string, order []string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range order {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create zip entry: %v", err)
+		}
+		w.Write([]byte(entries[name]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to write zip archive: %v", err)
+	}
+
+	writeFile(t, fp, buf.Bytes())
+}
@@ -1,15 +1,37 @@
 package fileutil
 
 import (
+	"cmp"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 )
 
-// CSVReader provides a helper/utility to read CSV file(s)
+// CSVReader provides a helper/utility to read CSV file(s).
+// Files compressed with gzip or bzip2 are decompressed on the fly, and each CSV entry of a zip archive
+// is read as a file of its own.
 type CSVReader struct {
 	FilePath string
+
+	// Reader, when set, is read instead of FilePath, which then only names the input (e.g. "-" for stdin).
+	// A stream can be read only once: every read continues where the previous one stopped, so ReadHeader
+	// followed by ReadAndProcessByRow works, but reading the rows twice doesn't. Day index ranges don't apply
+	Reader io.Reader
+
+	// Entry selects the CSV entry to read when FilePath is a zip archive
+	Entry string
+
+	// Encoding is the character encoding of the file, detected by default. A byte order mark is always dropped
+	Encoding Encoding
+
+	// Range restricts the rows read by a Loader to a byte range of the file, typically found with a DayIndex.
+	// The header is always read from the start of the file
+	Range *ByteRange
+
+	stream *streamFile
 }
 
 // NewCSVReader returns a CSVReader instance for a specified CSV file
@@ -19,42 +41,111 @@ func NewCSVReader(fp string) *CSVReader {
 	}
 }
 
-// ReadHeader reads ONLY the header of the specified CSV file
+// NewStreamCSVReader returns a CSVReader instance reading a CSV stream, e.g. stdin, named name
+func NewStreamCSVReader(name string, r io.Reader) *CSVReader {
+	return &CSVReader{
+		FilePath: name,
+		Reader:   r,
+	}
+}
+
+// Name identifies the file read, "archive.zip:entry.csv" for an entry of a zip archive
+func (r *CSVReader) Name() string {
+	if r.Entry != "" {
+		return r.FilePath + ":" + r.Entry
+	}
+	return r.FilePath
+}
+
+// Sources returns a reader for each CSV file behind the reader: one per CSV entry of a zip archive,
+// or the reader itself
+func (r *CSVReader) Sources() ([]*CSVReader, error) {
+	if r.Entry != "" || r.Reader != nil {
+		return []*CSVReader{r}, nil
+	}
+
+	compression, err := DetectCompression(r.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if compression != Zip {
+		return []*CSVReader{r}, nil
+	}
+
+	entries, err := zipEntries(r.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]*CSVReader, 0, len(entries))
+	for _, entry := range entries {
+		sources = append(sources, &CSVReader{FilePath: r.FilePath, Entry: entry, Encoding: r.Encoding, Range: r.Range})
+	}
+
+	return sources, nil
+}
+
+func (r *CSVReader) parts() ([]Source, error) {
+	sources, err := r.Sources()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]Source, 0, len(sources))
+	for _, source := range sources {
+		parts = append(parts, source)
+	}
+	return parts, nil
+}
+
+// ReadHeader reads ONLY the header of the specified CSV file, the first one of a zip archive
 func (r *CSVReader) ReadHeader() ([]string, error) {
-	f, err := os.Open(r.FilePath)
+	sources, err := r.Sources()
 	if err != nil {
-		return nil, fmt.Errorf("opening a csv file: %w", err)
+		return nil, err
 	}
-	defer f.Close()
 
-	reader := csv.NewReader(f)
-	header, err := reader.Read()
+	cf, err := sources[0].open()
 	if err != nil {
-		return nil, fmt.Errorf("reading CSV header: %w", err)
+		return nil, err
 	}
+	defer cf.Close()
 
-	return header, nil
+	return cf.header, nil
 }
 
-// ReadAndProcessByRow reads and processes a CSV file row by row, allows for streaming large file(s)
-func (r *CSVReader) ReadAndProcessByRow(processorFn func([]string) error) error {
-	f, err := os.Open(r.FilePath)
+// ReadAndProcessByRow reads and processes a CSV file row by row, allows for streaming large file(s).
+// The rows of every CSV entry of a zip archive are processed in archive order.
+// It stops with the context's error as soon as ctx is cancelled.
+func (r *CSVReader) ReadAndProcessByRow(ctx context.Context, processorFn func([]string) error) error {
+	sources, err := r.Sources()
 	if err != nil {
-		return fmt.Errorf("opening a csv file: %w", err)
+		return err
 	}
-	defer f.Close()
 
-	reader := csv.NewReader(f)
+	for _, source := range sources {
+		if err := source.processByRow(ctx, processorFn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-	// Skip header
-	_, err = reader.Read()
+func (r *CSVReader) processByRow(ctx context.Context, processorFn func([]string) error) error {
+	cf, err := r.open()
 	if err != nil {
-		return fmt.Errorf("reading CSV header: %w", err)
+		return err
 	}
+	defer cf.Close()
 
 	// read and process row by row
 	for {
-		row, err := reader.Read()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := cf.rows.Read()
 		if err == io.EOF {
 			break // end of file, stop
 		}
@@ -69,3 +160,158 @@ func (r *CSVReader) ReadAndProcessByRow(processorFn func([]string) error) error
 
 	return nil
 }
+
+// WithRange returns a copy of the reader restricted to the rows within the byte range
+func (r *CSVReader) WithRange(byteRange ByteRange) *CSVReader {
+	return &CSVReader{
+		FilePath: r.FilePath,
+		Reader:   r.Reader,
+		Entry:    r.Entry,
+		Encoding: r.Encoding,
+		Range:    &byteRange,
+	}
+}
+
+// segmentRows reads the rows of several sections of a file one after the other
+type segmentRows struct {
+	segments []rowSegment
+	current  int
+}
+
+// rowSegment reads the rows of a section of a file
+type rowSegment struct {
+	rows       *csv.Reader
+	lineOffset int // Added to the line numbers reported by rows to get the line numbers within the file
+}
+
+func (s *segmentRows) Read() ([]string, error) {
+	for ; s.current < len(s.segments); s.current++ {
+		row, err := s.segments[s.current].rows.Read()
+		if err != io.EOF {
+			return row, err
+		}
+	}
+	return nil, io.EOF
+}
+
+// FieldPos returns the position within the file of a field of the last row read
+func (s *segmentRows) FieldPos(field int) (line, column int) {
+	segment := s.segments[min(s.current, len(s.segments)-1)]
+	line, column = segment.rows.FieldPos(field)
+	return line + segment.lineOffset, column
+}
+
+// streamFile is the rowFile of a stream, it stays open as the stream can't be read again
+type streamFile struct {
+	cf  *rowFile
+	err error
+}
+
+// open opens the CSV file and reads its header, the caller must close the returned file
+func (r *CSVReader) open() (*rowFile, error) {
+	if r.Reader != nil {
+		return r.openStream()
+	}
+
+	f, err := os.Open(r.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening a csv file: %w", err)
+	}
+
+	content, compression, err := decompress(f, r.Entry)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if r.Range != nil && compression != Uncompressed {
+		content.Close()
+		return nil, fmt.Errorf("reading %s: %w", r.Name(), ErrCompressed)
+	}
+
+	text, enc, err := decodeText(content, r.Encoding)
+	if err != nil {
+		content.Close()
+		return nil, fmt.Errorf("reading %s: %w", r.Name(), err)
+	}
+
+	reader := csv.NewReader(text)
+	header, err := reader.Read()
+	if err != nil {
+		content.Close()
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	cf := &rowFile{Closer: content, header: header, rows: reader}
+
+	if r.Range != nil {
+		if enc.isUTF16() {
+			content.Close()
+			return nil, fmt.Errorf("reading %s: %w", r.Name(), ErrUTF16Range)
+		}
+
+		// The sections are in the encoding of the whole file, detected UTF-8 falling back to Windows-1252 in a
+		// section as in the whole file
+		sectionEnc := enc
+		if r.Encoding == EncodingAuto && enc == EncodingUTF8 {
+			sectionEnc = EncodingAuto
+		}
+
+		// The undated rows outside the range are read around it, in file order
+		sections := append([]UndatedRow{{Offset: r.Range.From, Size: r.Range.To - r.Range.From, Line: r.Range.Line}}, r.Range.Undated...)
+		slices.SortStableFunc(sections, func(a, b UndatedRow) int { return cmp.Compare(a.Offset, b.Offset) })
+
+		rows := &segmentRows{}
+		for _, section := range sections {
+			text, _, err := decodeText(io.NewSectionReader(f, section.Offset, section.Size), sectionEnc)
+			if err != nil {
+				content.Close()
+				return nil, fmt.Errorf("reading %s: %w", r.Name(), err)
+			}
+
+			// A new csv.Reader over a section counts lines from 1 again, and expects as many fields as the header has
+			sectionRows := csv.NewReader(text)
+			sectionRows.FieldsPerRecord = len(header)
+			rows.segments = append(rows.segments, rowSegment{rows: sectionRows, lineOffset: section.Line - 1})
+		}
+		cf.rows = rows
+	}
+
+	return cf, nil
+}
+
+// openStream reads the header of the stream on the first call, later calls continue with the rows left
+func (r *CSVReader) openStream() (*rowFile, error) {
+	if r.stream != nil {
+		return r.stream.cf, r.stream.err
+	}
+
+	r.stream = &streamFile{}
+	if r.Range != nil {
+		r.stream.err = fmt.Errorf("reading %s: streams can't be read by byte range", r.Name())
+		return nil, r.stream.err
+	}
+
+	content, err := decompressStream(r.Reader)
+	if err != nil {
+		r.stream.err = fmt.Errorf("reading %s: %w", r.Name(), err)
+		return nil, r.stream.err
+	}
+
+	text, _, err := decodeText(content, r.Encoding)
+	if err != nil {
+		r.stream.err = fmt.Errorf("reading %s: %w", r.Name(), err)
+		return nil, r.stream.err
+	}
+
+	reader := csv.NewReader(text)
+	header, err := reader.Read()
+	if err != nil {
+		r.stream.err = fmt.Errorf("reading CSV header: %w", err)
+		return nil, r.stream.err
+	}
+
+	// Closing is left to the owner of the stream
+	r.stream.cf = &rowFile{Closer: io.NopCloser(nil), header: header, rows: reader}
+	return r.stream.cf, nil
+}
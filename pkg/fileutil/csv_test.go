@@ -0,0 +1,72 @@
+package fileutil_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+func TestCSVReader_StreamIsReadOnce(t *testing.T) {
+	var gz bytes.Buffer
+	gzw := gzip.NewWriter(&gz)
+	gzw.Write([]byte("id,value\nA,1\nB,2\n"))
+	gzw.Close()
+
+	reader := fileutil.NewStreamCSVReader("-", &gz)
+
+	header, err := reader.ReadHeader()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(header, ",") != "id,value" {
+		t.Errorf("Expected header id,value, got %v", header)
+	}
+
+	// The rows follow the header read above
+	var ids []string
+	err = reader.ReadAndProcessByRow(context.Background(), func(row []string) error {
+		ids = append(ids, row[0])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(ids, ",") != "A,B" {
+		t.Errorf("Expected rows A,B, got %v", ids)
+	}
+
+	// Nothing is left to read
+	rows, err := newTestLoader().Load(context.Background(), reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(rows) != 0 {
+		t.Errorf("Expected no rows left, got %d", len(rows))
+	}
+}
+
+func TestCSVReader_StreamConcurrently(t *testing.T) {
+	lines := []string{"id,value"}
+	for i := 0; i < 100; i++ {
+		lines = append(lines, "ROW,1")
+	}
+
+	loader := newTestLoader()
+	loader.BatchSize = 7
+
+	rows, err := loader.LoadConcurrently(context.Background(), fileutil.NewStreamCSVReader("-", strings.NewReader(strings.Join(lines, "\n"))))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(rows) != 100 {
+		t.Errorf("Expected 100 rows, got %d", len(rows))
+	}
+}
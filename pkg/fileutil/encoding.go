@@ -0,0 +1,201 @@
+package fileutil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// ErrUTF16Range is returned when reading a UTF-16 file by byte range, its rows can't be indexed
+var ErrUTF16Range = errors.New("utf-16 files can't be read by byte range")
+
+// Encoding is the character encoding of a CSV file, rows are always decoded to UTF-8
+type Encoding string
+
+const (
+	// EncodingAuto detects the encoding from the byte order mark, or from the first bytes of the file:
+	// NUL bytes between ASCII characters mean UTF-16, invalid UTF-8 means Windows-1252, and UTF-8 otherwise.
+	// UTF-8 without a byte order mark still falls back to Windows-1252 at the first invalid byte further on
+	EncodingAuto        Encoding = ""
+	EncodingUTF8        Encoding = "utf-8"
+	EncodingUTF16LE     Encoding = "utf-16le"
+	EncodingUTF16BE     Encoding = "utf-16be"
+	EncodingWindows1252 Encoding = "windows-1252"
+	EncodingISO88591    Encoding = "iso-8859-1"
+)
+
+// sniffSize is how many bytes are looked at to detect the encoding
+const sniffSize = 4096
+
+var encodingNames = map[string]Encoding{
+	"":             EncodingAuto,
+	"auto":         EncodingAuto,
+	"utf-8":        EncodingUTF8,
+	"utf8":         EncodingUTF8,
+	"utf-16le":     EncodingUTF16LE,
+	"utf16le":      EncodingUTF16LE,
+	"utf-16be":     EncodingUTF16BE,
+	"utf16be":      EncodingUTF16BE,
+	"windows-1252": EncodingWindows1252,
+	"cp1252":       EncodingWindows1252,
+	"iso-8859-1":   EncodingISO88591,
+	"latin1":       EncodingISO88591,
+	"latin-1":      EncodingISO88591,
+}
+
+// ParseEncoding returns the encoding of a name such as "utf-16le", "cp1252" or "latin1", "auto" to detect it
+func ParseEncoding(name string) (Encoding, error) {
+	enc, ok := encodingNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return EncodingAuto, fmt.Errorf("unsupported encoding %q", name)
+	}
+	return enc, nil
+}
+
+// isUTF16 reports whether the encoding uses two bytes per ASCII character
+func (e Encoding) isUTF16() bool {
+	return e == EncodingUTF16LE || e == EncodingUTF16BE
+}
+
+// decodeText converts r from enc to UTF-8 and drops the byte order mark, it returns the encoding actually used
+func decodeText(r io.Reader, enc Encoding) (io.Reader, Encoding, error) {
+	br := bufio.NewReaderSize(r, sniffSize)
+	head, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, enc, fmt.Errorf("reading file: %w", err)
+	}
+
+	detected := enc == EncodingAuto
+	if detected {
+		enc = detectEncoding(head)
+	}
+
+	switch enc {
+	case EncodingUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder().Reader(br), enc, nil
+	case EncodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder().Reader(br), enc, nil
+	case EncodingWindows1252:
+		return charmap.Windows1252.NewDecoder().Reader(br), enc, nil
+	case EncodingISO88591:
+		return charmap.ISO8859_1.NewDecoder().Reader(br), enc, nil
+	case EncodingUTF8:
+		if bytes.HasPrefix(head, utf8BOM) {
+			br.Discard(len(utf8BOM))
+			return br, enc, nil
+		}
+		if detected {
+			// Windows-1252 bytes may only show up after the sniffed ones
+			return &utf8FallbackReader{r: br}, enc, nil
+		}
+		return br, enc, nil
+	default:
+		return nil, enc, fmt.Errorf("unsupported encoding %q", enc)
+	}
+}
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+)
+
+// detectEncoding guesses the encoding of a file from its first bytes
+func detectEncoding(head []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(head, utf8BOM):
+		return EncodingUTF8
+	case bytes.HasPrefix(head, utf16LEBOM):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(head, utf16BEBOM):
+		return EncodingUTF16BE
+	case len(head) >= 2 && head[0] != 0 && head[1] == 0:
+		return EncodingUTF16LE
+	case len(head) >= 2 && head[0] == 0 && head[1] != 0:
+		return EncodingUTF16BE
+	}
+
+	// The sniffed bytes may end in the middle of a character
+	if len(head) == sniffSize {
+		for i := 0; i < utf8.UTFMax-1 && len(head) > 0 && !utf8.RuneStart(head[len(head)-1]); i++ {
+			head = head[:len(head)-1]
+		}
+		if len(head) > 0 && head[len(head)-1] >= utf8.RuneSelf {
+			head = head[:len(head)-1]
+		}
+	}
+
+	if !utf8.Valid(head) {
+		return EncodingWindows1252
+	}
+	return EncodingUTF8
+}
+
+// utf8FallbackReader passes UTF-8 through up to the first invalid sequence, and decodes the rest as Windows-1252
+type utf8FallbackReader struct {
+	r        *bufio.Reader
+	valid    int       // Buffered bytes known to be valid UTF-8
+	fallback io.Reader // Windows-1252 decoder of the rest, once an invalid sequence was found
+}
+
+func (f *utf8FallbackReader) Read(p []byte) (int, error) {
+	if f.fallback != nil {
+		return f.fallback.Read(p)
+	}
+
+	if f.valid == 0 {
+		valid, err := f.scan()
+		if err != nil {
+			return 0, err
+		}
+		if valid == 0 {
+			f.fallback = charmap.Windows1252.NewDecoder().Reader(f.r)
+			return f.fallback.Read(p)
+		}
+		f.valid = valid
+	}
+
+	n, err := f.r.Read(p[:min(len(p), f.valid)])
+	f.valid -= n
+	return n, err
+}
+
+// scan returns how many of the buffered bytes are valid UTF-8, 0 when the next ones are an invalid sequence
+func (f *utf8FallbackReader) scan() (int, error) {
+	if _, err := f.r.Peek(1); err != nil {
+		return 0, err
+	}
+	buf, _ := f.r.Peek(f.r.Buffered())
+
+	i := 0
+	for i < len(buf) {
+		if buf[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(buf[i:])
+		if r != utf8.RuneError || size != 1 {
+			i += size
+			continue
+		}
+		if i > 0 || utf8.FullRune(buf) {
+			return i, nil
+		}
+
+		// A character cut by the end of the buffer, complete it
+		buf, _ = f.r.Peek(utf8.UTFMax)
+		if r, size = utf8.DecodeRune(buf); r == utf8.RuneError && size == 1 {
+			return 0, nil
+		}
+		return size, nil
+	}
+	return i, nil
+}
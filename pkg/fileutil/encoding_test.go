@@ -0,0 +1,203 @@
+package fileutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestLoader_Encodings(t *testing.T) {
+	const content = "id,value\ncafé,1\nnaïve,2\n"
+	const euroContent = "id,value\n€ fee,1\ncafé,2\n"
+
+	utf16 := func(endianness unicode.Endianness, bom unicode.BOMPolicy, s string) []byte {
+		encoded, err := unicode.UTF16(endianness, bom).NewEncoder().Bytes([]byte(s))
+		if err != nil {
+			t.Fatalf("Failed to encode test data: %v", err)
+		}
+		return encoded
+	}
+
+	windows1252, err := charmap.Windows1252.NewEncoder().Bytes([]byte(euroContent))
+	if err != nil {
+		t.Fatalf("Failed to encode test data: %v", err)
+	}
+
+	latin1, err := charmap.ISO8859_1.NewEncoder().Bytes([]byte(content))
+	if err != nil {
+		t.Fatalf("Failed to encode test data: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		content  []byte
+		encoding fileutil.Encoding
+		expected []string
+	}{
+		{"utf-8", []byte(content), fileutil.EncodingAuto, []string{"café", "naïve"}},
+		{"utf-8 with BOM", append([]byte("\xef\xbb\xbf"), content...), fileutil.EncodingAuto, []string{"café", "naïve"}},
+		{"utf-8 with BOM, explicit", append([]byte("\xef\xbb\xbf"), content...), fileutil.EncodingUTF8, []string{"café", "naïve"}},
+		{"utf-16le with BOM", utf16(unicode.LittleEndian, unicode.UseBOM, content), fileutil.EncodingAuto, []string{"café", "naïve"}},
+		{"utf-16le without BOM", utf16(unicode.LittleEndian, unicode.IgnoreBOM, content), fileutil.EncodingAuto, []string{"café", "naïve"}},
+		{"utf-16le, explicit", utf16(unicode.LittleEndian, unicode.IgnoreBOM, content), fileutil.EncodingUTF16LE, []string{"café", "naïve"}},
+		{"utf-16be with BOM", utf16(unicode.BigEndian, unicode.UseBOM, content), fileutil.EncodingAuto, []string{"café", "naïve"}},
+		{"utf-16be without BOM", utf16(unicode.BigEndian, unicode.IgnoreBOM, content), fileutil.EncodingAuto, []string{"café", "naïve"}},
+		{"utf-16be, explicit", utf16(unicode.BigEndian, unicode.UseBOM, content), fileutil.EncodingUTF16BE, []string{"café", "naïve"}},
+		{"windows-1252", windows1252, fileutil.EncodingAuto, []string{"€ fee", "café"}},
+		{"windows-1252, explicit", windows1252, fileutil.EncodingWindows1252, []string{"€ fee", "café"}},
+		{"iso-8859-1, explicit", latin1, fileutil.EncodingISO88591, []string{"café", "naïve"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "rows.csv")
+			writeFile(t, fp, tt.content)
+
+			src := fileutil.NewCSVReader(fp)
+			src.Encoding = tt.encoding
+
+			rows, err := newTestLoader().LoadConcurrently(context.Background(), src)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var ids []string
+			for _, row := range rows {
+				ids = append(ids, row.ID)
+			}
+
+			if !slices.Equal(ids, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestLoader_LateWindows1252(t *testing.T) {
+	// Plain ASCII beyond the sniffed bytes, then a Windows-1252 "é" and "€"
+	var content bytes.Buffer
+	content.WriteString("id,value\n")
+	for content.Len() < 3*4096 {
+		content.WriteString("ascii,1\n")
+	}
+	content.WriteString("caf\xe9,2\n\x80 fee,3\n")
+
+	fp := filepath.Join(t.TempDir(), "rows.csv")
+	writeFile(t, fp, content.Bytes())
+
+	sources := map[string]func() *fileutil.CSVReader{
+		"file": func() *fileutil.CSVReader {
+			return fileutil.NewCSVReader(fp)
+		},
+		"stream": func() *fileutil.CSVReader {
+			src := fileutil.NewCSVReader("stdin")
+			src.Reader = bytes.NewReader(content.Bytes())
+			return src
+		},
+	}
+
+	for name, newSource := range sources {
+		t.Run(name, func(t *testing.T) {
+			rows, err := newTestLoader().Load(context.Background(), newSource())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var ids []string
+			for _, row := range rows[len(rows)-2:] {
+				ids = append(ids, row.ID)
+			}
+
+			expected := []string{"café", "€ fee"}
+			if !slices.Equal(ids, expected) {
+				t.Errorf("Expected %q, got %q", expected, ids)
+			}
+		})
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := map[string]fileutil.Encoding{
+		"auto":         fileutil.EncodingAuto,
+		"UTF-8":        fileutil.EncodingUTF8,
+		"utf16le":      fileutil.EncodingUTF16LE,
+		"UTF-16BE":     fileutil.EncodingUTF16BE,
+		"cp1252":       fileutil.EncodingWindows1252,
+		"Windows-1252": fileutil.EncodingWindows1252,
+		"latin1":       fileutil.EncodingISO88591,
+	}
+
+	for name, expected := range tests {
+		enc, err := fileutil.ParseEncoding(name)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if enc != expected {
+			t.Errorf("Expected %q for %s, got %q", expected, name, enc)
+		}
+	}
+
+	if _, err := fileutil.ParseEncoding("ebcdic"); err == nil {
+		t.Errorf("Expected an error for an unsupported encoding")
+	}
+}
+
+func TestBuildDayIndex_Encodings(t *testing.T) {
+	// A BOM before the date column doesn't hide it
+	fp := filepath.Join(t.TempDir(), "rows.csv")
+	writeFile(t, fp, []byte("\xef\xbb\xbfdate,id\n2025-01-01,A\n2025-01-02,B\n"))
+
+	idx, err := fileutil.BuildDayIndex(fp, "date", "2006-01-02")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(idx.Days) != 2 {
+		t.Errorf("Expected 2 days, got %d", len(idx.Days))
+	}
+
+	// The rows of a range are decoded like the rest of the file
+	writeFile(t, fp, []byte("date,id\n2025-01-01,caf\xe9\n2025-01-02,\x80 fee\n"))
+
+	idx, err = fileutil.BuildDayIndex(fp, "date", "2006-01-02")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	day, _ := time.Parse("2006-01-02", "2025-01-02")
+	src := fileutil.NewCSVReader(fp).WithRange(idx.Range(day, day))
+	src.Encoding = fileutil.EncodingWindows1252
+
+	loader := &fileutil.Loader[string]{
+		Decoder: func([]string) (fileutil.RowDecoder[string], error) {
+			return func(row []string) (string, error) { return row[1], nil }, nil
+		},
+	}
+
+	ids, err := loader.Load(context.Background(), src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !slices.Equal(ids, []string{"€ fee"}) {
+		t.Errorf("Expected [€ fee], got %q", ids)
+	}
+
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte("date,id\n2025-01-01,A\n"))
+	if err != nil {
+		t.Fatalf("Failed to encode test data: %v", err)
+	}
+	writeFile(t, fp, utf16)
+
+	if _, err := fileutil.BuildDayIndex(fp, "date", "2006-01-02"); !errors.Is(err, fileutil.ErrUTF16Range) {
+		t.Errorf("Expected ErrUTF16Range, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package fileutil
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dayLayout = "2006-01-02"
+
+// ErrNotSorted is returned when indexing a CSV file whose rows aren't sorted by date
+var ErrNotSorted = errors.New("csv file is not sorted by date")
+
+// DayIndex is a sidecar index of a date-sorted CSV file: the byte offset of the first row of every day.
+// It lets readers seek straight to the rows of a date range instead of scanning the whole file.
+type DayIndex struct {
+	FileSize    int64        `json:"file_size"`         // Size of the indexed file
+	FileModTime int64        `json:"file_mod_time"`     // Modification time of the indexed file, in Unix nanoseconds
+	Days        []DayOffset  `json:"days"`              // Sorted by day
+	Undated     []UndatedRow `json:"undated,omitempty"` // Rows whose date couldn't be parsed, in file order
+}
+
+// DayOffset locates the first row of a day
+type DayOffset struct {
+	Day    string `json:"day"`    // YYYY-MM-DD
+	Offset int64  `json:"offset"` // Byte offset of the row
+	Line   int    `json:"line"`   // Line number of the row, the header being line 1
+}
+
+// UndatedRow locates a row whose date couldn't be parsed. It belongs to no day, so it's read with every range
+// for its reject to be reported as when the whole file is scanned
+type UndatedRow struct {
+	Offset int64 `json:"offset"` // Byte offset of the row
+	Size   int64 `json:"size"`   // Byte size of the row
+	Line   int   `json:"line"`   // Line number of the row, the header being line 1
+}
+
+// ByteRange is a contiguous range of rows of a CSV file, From inclusive and To exclusive
+type ByteRange struct {
+	From int64
+	To   int64
+	Line int // Line number of the row starting at From
+
+	// Undated are the rows without a date outside the range, read along with it in file order
+	Undated []UndatedRow
+}
+
+// IndexPath returns the default sidecar index path of a CSV file
+func IndexPath(csvPath string) string {
+	return csvPath + ".idx"
+}
+
+// BuildDayIndex indexes a CSV file sorted by the date column, whose values are parsed with layout.
+// Rows with an unparsable date are recorded apart, and compressed files can't be indexed.
+func BuildDayIndex(filePath, dateColumn, layout string) (*DayIndex, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening a csv file: %w", err)
+	}
+	defer f.Close()
+
+	compression, err := detectCompression(f)
+	if err != nil {
+		return nil, err
+	}
+	if compression != Uncompressed {
+		return nil, fmt.Errorf("indexing %s file: %w", compression, ErrCompressed)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv file info: %w", err)
+	}
+
+	// Offsets are counted in the bytes of the file, so the rows must be found without decoding it.
+	// That works for every supported encoding but UTF-16, whose ASCII characters take two bytes
+	head := make([]byte, sniffSize)
+	n, err := f.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("reading csv file: %w", err)
+	}
+	if detectEncoding(head[:n]).isUTF16() {
+		return nil, fmt.Errorf("indexing %s: %w", filePath, ErrUTF16Range)
+	}
+
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], string(utf8BOM))
+	}
+
+	column := -1
+	for i, field := range header {
+		if strings.EqualFold(field, dateColumn) {
+			column = i
+			break
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("required field '%s' not found in CSV header", dateColumn)
+	}
+
+	idx := &DayIndex{
+		FileSize:    stat.Size(),
+		FileModTime: stat.ModTime().UnixNano(),
+	}
+
+	var lastDay time.Time
+	for {
+		offset := reader.InputOffset()
+
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV row: %w", err)
+		}
+
+		var t time.Time
+		if len(row) > column {
+			t, err = time.Parse(layout, row[column])
+		}
+		if len(row) <= column || err != nil {
+			line, _ := reader.FieldPos(0)
+			idx.Undated = append(idx.Undated, UndatedRow{Offset: offset, Size: reader.InputOffset() - offset, Line: line})
+			continue
+		}
+
+		day := t.Truncate(24 * time.Hour)
+		switch {
+		case len(idx.Days) > 0 && day.Before(lastDay):
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, ErrNotSorted)
+		case len(idx.Days) == 0 || day.After(lastDay):
+			line, _ := reader.FieldPos(0)
+			idx.Days = append(idx.Days, DayOffset{Day: day.Format(dayLayout), Offset: offset, Line: line})
+			lastDay = day
+		}
+	}
+
+	return idx, nil
+}
+
+// ReadDayIndex reads a sidecar index written by WriteFile
+func ReadDayIndex(path string) (*DayIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading day index: %w", err)
+	}
+
+	var idx DayIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("decoding day index: %w", err)
+	}
+
+	return &idx, nil
+}
+
+// WriteFile writes the index to path
+func (idx *DayIndex) WriteFile(path string) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("encoding day index: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing day index: %w", err)
+	}
+
+	return nil
+}
+
+// IsFresh reports whether the index still describes the CSV file, i.e. the file wasn't modified since it was indexed
+func (idx *DayIndex) IsFresh(csvPath string) bool {
+	stat, err := os.Stat(csvPath)
+	if err != nil {
+		return false
+	}
+
+	return stat.Size() == idx.FileSize && stat.ModTime().UnixNano() == idx.FileModTime
+}
+
+// Range returns the byte range holding the rows dated between startDate and endDate, both inclusive, with the
+// undated rows outside of it
+func (idx *DayIndex) Range(startDate, endDate time.Time) ByteRange {
+	startDay := startDate.Truncate(24 * time.Hour).Format(dayLayout)
+	endDay := endDate.Truncate(24 * time.Hour).Format(dayLayout)
+
+	// YYYY-MM-DD strings sort like the days they represent
+	from := sort.Search(len(idx.Days), func(i int) bool { return idx.Days[i].Day >= startDay })
+	to := sort.Search(len(idx.Days), func(i int) bool { return idx.Days[i].Day > endDay })
+
+	r := ByteRange{From: idx.FileSize, To: idx.FileSize}
+	if from < to {
+		r.From, r.Line = idx.Days[from].Offset, idx.Days[from].Line
+		if to < len(idx.Days) {
+			r.To = idx.Days[to].Offset
+		}
+	}
+
+	for _, row := range idx.Undated {
+		if row.Offset < r.From || row.Offset >= r.To {
+			r.Undated = append(r.Undated, row)
+		}
+	}
+
+	return r
+}
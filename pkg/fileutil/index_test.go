@@ -0,0 +1,173 @@
+package fileutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+func TestDayIndex_RangeLoadsOnlyRequestedDays(t *testing.T) {
+	lines := []string{"id,value,date"}
+	for day := 1; day <= 20; day++ {
+		for i := 0; i < 3; i++ {
+			lines = append(lines, fmt.Sprintf("D%02d-%d,%d,2025-01-%02d", day, i, day*10+i, day))
+		}
+	}
+	fp := writeTestCSV(t, lines)
+
+	idx, err := fileutil.BuildDayIndex(fp, "date", "2006-01-02")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(idx.Days) != 20 {
+		t.Fatalf("Expected 20 indexed days, got %d", len(idx.Days))
+	}
+
+	indexPath := fileutil.IndexPath(fp)
+	if err := idx.WriteFile(indexPath); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	idx, err = fileutil.ReadDayIndex(indexPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !idx.IsFresh(fp) {
+		t.Error("Expected the index to be fresh")
+	}
+
+	startDate, _ := time.Parse("2006-01-02", "2025-01-05")
+	endDate, _ := time.Parse("2006-01-02", "2025-01-07")
+
+	var rejected []int
+	loader := newTestLoader()
+	loader.Decoder = dateAgnosticDecoder(loader.Decoder)
+	loader.Reject = func(r fileutil.Reject) { rejected = append(rejected, r.Line) }
+	loader.BatchSize = 2
+
+	src := fileutil.NewCSVReader(fp).WithRange(idx.Range(startDate, endDate))
+	for _, load := range []func(context.Context, fileutil.Source) ([]testRow, error){loader.Load, loader.LoadConcurrently} {
+		rows, err := load(context.Background(), src)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(rows) != 9 || rows[0].ID != "D05-0" || rows[8].ID != "D07-2" {
+			t.Errorf("Expected the 9 rows of Jan 5-7, got %v", rows)
+		}
+	}
+
+	// Out of range dates produce an empty range
+	startDate, _ = time.Parse("2006-01-02", "2025-02-01")
+	endDate, _ = time.Parse("2006-01-02", "2025-02-28")
+
+	rows, err := loader.Load(context.Background(), fileutil.NewCSVReader(fp).WithRange(idx.Range(startDate, endDate)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("Expected no rows, got %d", len(rows))
+	}
+
+	// Line numbers stay relative to the whole file
+	if err := os.WriteFile(fp, []byte("id,value,date\nA,1,2025-01-01\nB,x,2025-01-02\n"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite test CSV: %v", err)
+	}
+	if idx.IsFresh(fp) {
+		t.Error("Expected the index to be stale after the file changed")
+	}
+
+	idx, err = fileutil.BuildDayIndex(fp, "date", "2006-01-02")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	day2, _ := time.Parse("2006-01-02", "2025-01-02")
+	if _, err := loader.Load(context.Background(), fileutil.NewCSVReader(fp).WithRange(idx.Range(day2, day2))); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rejected) != 1 || rejected[0] != 3 {
+		t.Errorf("Expected line 3 to be rejected, got %v", rejected)
+	}
+}
+
+func TestDayIndex_RangeReadsUndatedRows(t *testing.T) {
+	fp := writeTestCSV(t, []string{
+		"id,value,date",
+		"U1,x,n/a",
+		"A,1,2025-01-01",
+		"B,2,2025-01-02",
+		"U2,y,",
+		"C,3,2025-01-02",
+		"U3,z,2025-13-40",
+		"D,4,2025-01-03",
+	})
+
+	idx, err := fileutil.BuildDayIndex(fp, "date", "2006-01-02")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(idx.Days) != 3 || len(idx.Undated) != 3 {
+		t.Fatalf("Expected 3 days and 3 undated rows, got %+v", idx)
+	}
+
+	// The undated rows outside the range are read around it in file order, so their rejects are reported as
+	// when the whole file is scanned
+	var rejected []int
+	loader := newTestLoader()
+	loader.Decoder = dateAgnosticDecoder(loader.Decoder)
+	loader.Reject = func(r fileutil.Reject) { rejected = append(rejected, r.Line) }
+
+	day2, _ := time.Parse("2006-01-02", "2025-01-02")
+	rows, err := loader.Load(context.Background(), fileutil.NewCSVReader(fp).WithRange(idx.Range(day2, day2)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(rows) != 2 || rows[0].ID != "B" || rows[1].ID != "C" {
+		t.Errorf("Expected the rows of Jan 2, got %v", rows)
+	}
+
+	if !slices.Equal(rejected, []int{2, 5, 7}) {
+		t.Errorf("Expected lines 2, 5 and 7 to be rejected, got %v", rejected)
+	}
+
+	// Even when no row is in the range
+	rejected = nil
+	day5, _ := time.Parse("2006-01-02", "2025-01-05")
+	if _, err := loader.Load(context.Background(), fileutil.NewCSVReader(fp).WithRange(idx.Range(day5, day5))); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !slices.Equal(rejected, []int{2, 5, 7}) {
+		t.Errorf("Expected lines 2, 5 and 7 to be rejected, got %v", rejected)
+	}
+}
+
+func TestBuildDayIndex_NotSorted(t *testing.T) {
+	fp := writeTestCSV(t, []string{"id,value,date", "A,1,2025-01-02", "B,2,2025-01-01"})
+
+	if _, err := fileutil.BuildDayIndex(fp, "date", "2006-01-02"); !errors.Is(err, fileutil.ErrNotSorted) {
+		t.Errorf("Expected ErrNotSorted, got %v", err)
+	}
+}
+
+// dateAgnosticDecoder adapts the test decoder to files with a trailing date column
+func dateAgnosticDecoder(decoder func([]string) (fileutil.RowDecoder[testRow], error)) func([]string) (fileutil.RowDecoder[testRow], error) {
+	return func(header []string) (fileutil.RowDecoder[testRow], error) {
+		decode, err := decoder(header[:2])
+		if err != nil {
+			return nil, err
+		}
+		return func(row []string) (testRow, error) { return decode(row[:2]) }, nil
+	}
+}
@@ -0,0 +1,363 @@
+package fileutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultBatchSize = 1000
+	defaultWorkers   = 4
+)
+
+// errStopIteration stops a Loader when the consumer of a stream stopped iterating
+var errStopIteration = errors.New("iteration stopped")
+
+// RowDecoder converts a single CSV row into a value of type T
+type RowDecoder[T any] func(row []string) (T, error)
+
+// Reject describes a CSV row that couldn't be decoded
+type Reject struct {
+	File string // Name of the file the row was read from, see CSVReader.Name
+	Line int    // Line number of the row within the file, the header being line 1
+	Row  []string
+	Err  error
+}
+
+// Source is a file whose rows a Loader reads, a CSVReader or an XLSXReader
+type Source interface {
+	// Name identifies the file in rejects
+	Name() string
+
+	// parts returns the files behind the source, read one after the other
+	parts() ([]Source, error)
+
+	// open reads the header of the file, and returns it positioned at its first row
+	open() (*rowFile, error)
+}
+
+// rowReader reads the rows after the header, *csv.Reader implements it
+type rowReader interface {
+	Read() (record []string, err error)
+	FieldPos(field int) (line, column int)
+}
+
+// rowFile is an opened file whose header was read, positioned at its first row (in range)
+type rowFile struct {
+	io.Closer
+	header     []string
+	rows       rowReader
+	lineOffset int // Added to the line numbers reported by rows to get the line numbers within the file
+}
+
+// Loader streams the rows of a CSV file into values of type T.
+// The same Loader can read a file sequentially or with a pool of workers, both yield values in file order.
+// The CSV entries of a zip archive are read one after the other, each with its own header.
+type Loader[T any] struct {
+	// Decoder builds the RowDecoder from the header row, typically to locate columns by name
+	Decoder func(header []string) (RowDecoder[T], error)
+
+	// Filter keeps only the decoded values it returns true for. A nil Filter keeps everything
+	Filter func(T) bool
+
+	// Reject receives every row the decoder failed on, in file order and from a single goroutine.
+	// A nil Reject drops those rows silently
+	Reject func(Reject)
+
+	BatchSize int // Rows per batch handed to a worker, defaults to 1000
+	Workers   int // Number of concurrent decoding workers, defaults to 4
+}
+
+// rowBatch is a batch of raw CSV rows, seq is its position within the file
+type rowBatch struct {
+	seq   int
+	lines []int
+	rows  [][]string
+}
+
+// decodedBatch holds the values and rejects decoded from the rowBatch with the same seq
+type decodedBatch[T any] struct {
+	seq     int
+	values  []T
+	rejects []Reject
+}
+
+// Load reads and decodes the whole file on the calling goroutine
+func (l *Loader[T]) Load(ctx context.Context, src Source) ([]T, error) {
+	return l.collect(ctx, src, false)
+}
+
+// LoadConcurrently reads the file on one goroutine and decodes batches of rows on a pool of workers.
+// The first error cancels every stage and is returned once they all stopped, so no goroutine outlives the call.
+func (l *Loader[T]) LoadConcurrently(ctx context.Context, src Source) ([]T, error) {
+	return l.collect(ctx, src, true)
+}
+
+func (l *Loader[T]) collect(ctx context.Context, src Source, concurrent bool) ([]T, error) {
+	var values []T
+	err := l.run(ctx, src, concurrent, func(batch decodedBatch[T]) error {
+		values = append(values, batch.values...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Stream yields the decoded values in file order as the file is read on the calling goroutine.
+// Only one batch of rows is held in memory at a time
+func (l *Loader[T]) Stream(ctx context.Context, src Source) iter.Seq2[T, error] {
+	return l.stream(ctx, src, false)
+}
+
+// StreamConcurrently yields the decoded values in file order while batches are decoded by a pool of workers.
+// Only the batches in flight are held in memory, and breaking out of the loop stops every stage
+func (l *Loader[T]) StreamConcurrently(ctx context.Context, src Source) iter.Seq2[T, error] {
+	return l.stream(ctx, src, true)
+}
+
+func (l *Loader[T]) stream(ctx context.Context, src Source, concurrent bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := l.run(ctx, src, concurrent, func(batch decodedBatch[T]) error {
+			for _, value := range batch.values {
+				if !yield(value, nil) {
+					return errStopIteration
+				}
+			}
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errStopIteration) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// run decodes every row after the header and hands the batches to emit in file order
+func (l *Loader[T]) run(ctx context.Context, src Source, concurrent bool, emit func(decodedBatch[T]) error) error {
+	sources, err := src.parts()
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		if err := l.runSource(ctx, source, concurrent, emit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *Loader[T]) runSource(ctx context.Context, src Source, concurrent bool, emit func(decodedBatch[T]) error) error {
+	cf, err := src.open()
+	if err != nil {
+		return err
+	}
+	defer cf.Close()
+
+	decode, err := l.Decoder(cf.header)
+	if err != nil {
+		return err
+	}
+
+	// Rejects are reported here, so the sink never has to be safe for concurrent use
+	emitWithRejects := func(batch decodedBatch[T]) error {
+		if l.Reject != nil {
+			for _, reject := range batch.rejects {
+				reject.File = src.Name()
+				l.Reject(reject)
+			}
+		}
+		return emit(batch)
+	}
+
+	if !concurrent {
+		return l.runSequentially(ctx, cf, decode, emitWithRejects)
+	}
+	return l.runConcurrently(ctx, cf, decode, emitWithRejects)
+}
+
+func (l *Loader[T]) runSequentially(ctx context.Context, cf *rowFile, decode RowDecoder[T], emit func(decodedBatch[T]) error) error {
+	return readBatches(ctx, cf, l.batchSize(), func(batch rowBatch) error {
+		return emit(l.decodeBatch(batch, decode))
+	})
+}
+
+func (l *Loader[T]) runConcurrently(ctx context.Context, cf *rowFile, decode RowDecoder[T], emit func(decodedBatch[T]) error) error {
+	workers := l.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	// Cancelled when emit fails, so the pipeline stops even though no stage failed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The first failing stage cancels gctx, which unblocks every other stage
+	g, gctx := errgroup.WithContext(ctx)
+
+	jobs := make(chan rowBatch, workers)
+	results := make(chan decodedBatch[T], workers)
+
+	// Read and distribute batches of CSV records to workers
+	g.Go(func() error {
+		defer close(jobs) // Close jobs channel when done reading
+
+		return readBatches(gctx, cf, l.batchSize(), func(batch rowBatch) error {
+			select {
+			case jobs <- batch:
+				return nil
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+		})
+	})
+
+	// Start the worker pool, results is closed once every worker has returned
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		g.Go(func() error {
+			defer wg.Done()
+
+			for {
+				var batch rowBatch
+				var ok bool
+
+				select {
+				case batch, ok = <-jobs:
+					if !ok {
+						return nil
+					}
+				case <-gctx.Done():
+					return gctx.Err()
+				}
+
+				// Every batch is sent, even an empty one, so the collector can restore file order
+				select {
+				case results <- l.decodeBatch(batch, decode):
+				case <-gctx.Done():
+					return gctx.Err()
+				}
+			}
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Batches arrive in any order, they are held back until all the batches before them were emitted.
+	// The loop drains results until every worker is gone, so no stage can stay blocked on a send
+	var emitErr error
+	pending := make(map[int]decodedBatch[T])
+	next := 0
+
+	for batch := range results {
+		if emitErr != nil {
+			continue
+		}
+
+		pending[batch.seq] = batch
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+
+			if emitErr = emit(ready); emitErr != nil {
+				cancel()
+				break
+			}
+		}
+	}
+
+	if err := g.Wait(); err != nil && emitErr == nil {
+		return err
+	}
+
+	return emitErr
+}
+
+// decodeBatch decodes and filters the rows of a batch
+func (l *Loader[T]) decodeBatch(batch rowBatch, decode RowDecoder[T]) decodedBatch[T] {
+	decoded := decodedBatch[T]{
+		seq:    batch.seq,
+		values: make([]T, 0, len(batch.rows)),
+	}
+
+	for i, row := range batch.rows {
+		value, err := decode(row)
+		if err != nil {
+			// Resilient. We try to process as much row as possible
+			decoded.rejects = append(decoded.rejects, Reject{Line: batch.lines[i], Row: row, Err: err})
+			continue
+		}
+
+		if l.Filter != nil && !l.Filter(value) {
+			continue
+		}
+
+		decoded.values = append(decoded.values, value)
+	}
+
+	return decoded
+}
+
+func (l *Loader[T]) batchSize() int {
+	if l.BatchSize <= 0 {
+		return defaultBatchSize
+	}
+	return l.BatchSize
+}
+
+// readBatches reads the remaining CSV records and groups them into sequence-numbered batches
+func readBatches(ctx context.Context, cf *rowFile, batchSize int, send func(rowBatch) error) error {
+	batch := rowBatch{}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := cf.rows.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading CSV record: %w", err)
+		}
+
+		line, _ := cf.rows.FieldPos(0)
+		batch.lines = append(batch.lines, line+cf.lineOffset)
+		batch.rows = append(batch.rows, record)
+
+		// When batch is full, send it to a worker
+		if len(batch.rows) >= batchSize {
+			if err := send(batch); err != nil {
+				return err
+			}
+			batch = rowBatch{seq: batch.seq + 1}
+		}
+	}
+
+	// Send any remaining records in the last batch
+	if len(batch.rows) > 0 {
+		return send(batch)
+	}
+
+	return nil
+}
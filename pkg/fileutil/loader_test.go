@@ -0,0 +1,244 @@
+package fileutil_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+type testRow struct {
+	ID    string
+	Value int
+}
+
+// newTestLoader decodes "id,value" rows, rows with a non-numeric value are rejected
+func newTestLoader() *fileutil.Loader[testRow] {
+	return &fileutil.Loader[testRow]{
+		Decoder: func(header []string) (fileutil.RowDecoder[testRow], error) {
+			if len(header) != 2 || header[0] != "id" || header[1] != "value" {
+				return nil, fmt.Errorf("unexpected header %v", header)
+			}
+
+			return func(row []string) (testRow, error) {
+				value, err := strconv.Atoi(row[1])
+				if err != nil {
+					return testRow{}, err
+				}
+				return testRow{ID: row[0], Value: value}, nil
+			}, nil
+		},
+	}
+}
+
+func TestLoader_LoadAndLoadConcurrentlyAgree(t *testing.T) {
+	lines := []string{"id,value"}
+	for i := 0; i < 1000; i++ {
+		value := strconv.Itoa(i)
+		if i%97 == 0 {
+			value = "n/a"
+		}
+		lines = append(lines, fmt.Sprintf("ROW-%04d,%s", i, value))
+	}
+	fp := writeTestCSV(t, lines)
+
+	for _, workers := range []int{1, 3, 16} {
+		var seqRejects, concRejects []int
+
+		loader := newTestLoader()
+		loader.BatchSize = 9
+		loader.Workers = workers
+		loader.Filter = func(r testRow) bool { return r.Value%2 == 0 }
+
+		loader.Reject = func(r fileutil.Reject) { seqRejects = append(seqRejects, r.Line) }
+		sequential, err := loader.Load(context.Background(), fileutil.NewCSVReader(fp))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		loader.Reject = func(r fileutil.Reject) { concRejects = append(concRejects, r.Line) }
+		concurrent, err := loader.LoadConcurrently(context.Background(), fileutil.NewCSVReader(fp))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(sequential) != 494 {
+			t.Errorf("Expected 494 rows, got %d", len(sequential))
+		}
+
+		if fmt.Sprint(sequential) != fmt.Sprint(concurrent) {
+			t.Errorf("Expected concurrent rows to match sequential rows with %d workers", workers)
+		}
+
+		// Rejected rows are ROW-0000, ROW-0097, ... and reported with their line number, in file order
+		if len(seqRejects) != 11 || seqRejects[0] != 2 || seqRejects[1] != 99 {
+			t.Errorf("Unexpected rejected lines %v", seqRejects)
+		}
+		if fmt.Sprint(seqRejects) != fmt.Sprint(concRejects) {
+			t.Errorf("Expected rejects %v, got %v with %d workers", seqRejects, concRejects, workers)
+		}
+	}
+}
+
+func TestLoader_ReadErrorMidFile(t *testing.T) {
+	lines := []string{"id,value"}
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, fmt.Sprintf("ROW-%04d,%d", i, i))
+		if i == 600 {
+			lines = append(lines, "ROW-BROKEN") // wrong number of fields
+		}
+	}
+	fp := writeTestCSV(t, lines)
+
+	baseline := runtime.NumGoroutine()
+
+	for _, workers := range []int{1, 4, 32} {
+		loader := newTestLoader()
+		loader.BatchSize = 5
+		loader.Workers = workers
+
+		rows, err := loader.LoadConcurrently(context.Background(), fileutil.NewCSVReader(fp))
+		if !errors.Is(err, csv.ErrFieldCount) {
+			t.Errorf("Expected csv.ErrFieldCount with %d workers, got %v", workers, err)
+		}
+		if rows != nil {
+			t.Errorf("Expected no rows on error, got %d", len(rows))
+		}
+	}
+
+	if _, err := newTestLoader().Load(context.Background(), fileutil.NewCSVReader(fp)); !errors.Is(err, csv.ErrFieldCount) {
+		t.Errorf("Expected csv.ErrFieldCount, got %v", err)
+	}
+
+	waitForGoroutines(t, baseline)
+}
+
+func TestLoader_DecoderError(t *testing.T) {
+	fp := writeTestCSV(t, []string{"name,amount", "a,1"})
+
+	if _, err := newTestLoader().LoadConcurrently(context.Background(), fileutil.NewCSVReader(fp)); err == nil {
+		t.Error("Expected an error for an unexpected header")
+	}
+}
+
+func TestLoader_CancelledContext(t *testing.T) {
+	fp := writeTestCSV(t, []string{"id,value", "a,1", "b,2"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	baseline := runtime.NumGoroutine()
+
+	if _, err := newTestLoader().Load(ctx, fileutil.NewCSVReader(fp)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	if _, err := newTestLoader().LoadConcurrently(ctx, fileutil.NewCSVReader(fp)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	waitForGoroutines(t, baseline)
+}
+
+// writeTestCSV writes lines into a CSV file inside a temporary directory and returns its path
+func writeTestCSV(t *testing.T, lines []string) string {
+	t.Helper()
+
+	fp := filepath.Join(t.TempDir(), "rows.csv")
+	if err := os.WriteFile(fp, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test CSV: %v", err)
+	}
+
+	return fp
+}
+
+// waitForGoroutines fails the test if goroutines started by the test are still running shortly after it finished
+func waitForGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected goroutines to return to %d, still %d running", baseline, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLoader_StreamYieldsInFileOrder(t *testing.T) {
+	lines := []string{"id,value"}
+	for i := 0; i < 500; i++ {
+		lines = append(lines, fmt.Sprintf("ROW-%04d,%d", i, i))
+	}
+	fp := writeTestCSV(t, lines)
+
+	loader := newTestLoader()
+	loader.BatchSize = 7
+	loader.Workers = 4
+
+	expected, err := loader.Load(context.Background(), fileutil.NewCSVReader(fp))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	baseline := runtime.NumGoroutine()
+
+	for _, stream := range []func(context.Context, fileutil.Source) iter.Seq2[testRow, error]{loader.Stream, loader.StreamConcurrently} {
+		var rows []testRow
+		for row, err := range stream(context.Background(), fileutil.NewCSVReader(fp)) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			rows = append(rows, row)
+		}
+
+		if fmt.Sprint(rows) != fmt.Sprint(expected) {
+			t.Error("Expected streamed rows to match loaded rows")
+		}
+
+		// Breaking out of the loop early stops the reader and the workers
+		count := 0
+		for _, err := range stream(context.Background(), fileutil.NewCSVReader(fp)) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			count++
+			if count == 10 {
+				break
+			}
+		}
+	}
+
+	waitForGoroutines(t, baseline)
+}
+
+func TestLoader_StreamYieldsReadError(t *testing.T) {
+	fp := writeTestCSV(t, []string{"id,value", "a,1", "b", "c,3"})
+
+	var rows int
+	var lastErr error
+	for _, err := range newTestLoader().StreamConcurrently(context.Background(), fileutil.NewCSVReader(fp)) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		rows++
+	}
+
+	if !errors.Is(lastErr, csv.ErrFieldCount) {
+		t.Errorf("Expected csv.ErrFieldCount, got %v", lastErr)
+	}
+	if rows != 0 {
+		t.Errorf("Expected the error before any row of the failing batch, got %d rows", rows)
+	}
+}
@@ -0,0 +1,66 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// inputExtensions are the extensions of the files picked from a directory
+var inputExtensions = []string{".csv", ".csv.gz", ".csv.bz2", ".zip", ".xlsx"}
+
+// ExpandPaths resolves a path to the input files it designates, sorted by name:
+// every CSV, compressed CSV, zip or XLSX file of a directory, the files (not directories) matching a glob pattern such as
+// "statements/2025-01/*.csv", or the path itself otherwise.
+func ExpandPaths(pattern string) ([]string, error) {
+	if strings.ContainsAny(pattern, "*?[") {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("expanding %s: %w", pattern, err)
+		}
+
+		matches = slices.DeleteFunc(matches, func(match string) bool {
+			stat, err := os.Stat(match)
+			return err == nil && stat.IsDir()
+		})
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no file matches %s", pattern)
+		}
+
+		slices.Sort(matches)
+		return matches, nil
+	}
+
+	stat, err := os.Stat(pattern)
+	if err != nil || !stat.IsDir() {
+		return []string{pattern}, nil // A missing file is reported when it's read
+	}
+
+	entries, err := os.ReadDir(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory %s: %w", pattern, err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || !hasInputExtension(entry.Name()) {
+			continue
+		}
+		paths = append(paths, filepath.Join(pattern, entry.Name()))
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("directory %s holds no input file", pattern)
+	}
+
+	return paths, nil
+}
+
+func hasInputExtension(name string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(inputExtensions, func(ext string) bool {
+		return strings.HasSuffix(name, ext)
+	})
+}
@@ -0,0 +1,58 @@
+package fileutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+)
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.csv", "a.csv.gz", "c.zip", "a.csv.idx", "notes.txt"} {
+		writeFile(t, filepath.Join(dir, name), []byte("id,value\n"))
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.csv"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{dir, []string{"a.csv.gz", "b.csv", "c.zip"}},
+		{filepath.Join(dir, "*.csv"), []string{"b.csv"}},
+		{filepath.Join(dir, "b.csv"), []string{"b.csv"}},
+		{filepath.Join(dir, "missing.csv"), []string{"missing.csv"}},
+	}
+
+	for _, tt := range tests {
+		paths, err := fileutil.ExpandPaths(tt.pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.pattern, err)
+		}
+
+		var names []string
+		for _, fp := range paths {
+			names = append(names, filepath.Base(fp))
+		}
+
+		if !slices.Equal(names, tt.expected) {
+			t.Errorf("Expected %v for %s, got %v", tt.expected, tt.pattern, names)
+		}
+	}
+}
+
+func TestExpandPaths_NoMatch(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := fileutil.ExpandPaths(filepath.Join(dir, "*.csv")); err == nil {
+		t.Errorf("Expected an error for a glob matching nothing")
+	}
+
+	if _, err := fileutil.ExpandPaths(dir); err == nil {
+		t.Errorf("Expected an error for a directory without csv files")
+	}
+}
@@ -0,0 +1,187 @@
+package fileutil
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXReader reads the rows of an Excel worksheet, for a Loader, like a CSVReader does for CSV files.
+// Cells are read as stored rather than as displayed, so numbers keep all their digits, e.g. "1234.5"
+// whatever the number format. Excel stores dates as serial numbers: those of DateColumns are formatted
+// with DateLayout, so the rows look like the ones of a CSV export.
+type XLSXReader struct {
+	FilePath string
+
+	Sheet     string // Worksheet to read, defaults to the first one
+	HeaderRow int    // Row number of the header, defaults to 1. Rows above it are ignored
+
+	DateColumns []string // Names of the columns holding dates
+	DateLayout  string   // Layout serial dates are formatted with
+}
+
+// NewXLSXReader returns an XLSXReader instance for the first sheet of a workbook
+func NewXLSXReader(fp string) *XLSXReader {
+	return &XLSXReader{
+		FilePath:  fp,
+		HeaderRow: 1,
+	}
+}
+
+// Name identifies the sheet read, "workbook.xlsx:Sheet1"
+func (r *XLSXReader) Name() string {
+	if r.Sheet == "" {
+		return r.FilePath
+	}
+	return r.FilePath + ":" + r.Sheet
+}
+
+func (r *XLSXReader) parts() ([]Source, error) {
+	return []Source{r}, nil
+}
+
+func (r *XLSXReader) open() (*rowFile, error) {
+	f, err := excelize.OpenFile(r.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening an xlsx file: %w", err)
+	}
+
+	xf, err := r.openSheet(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return xf, nil
+}
+
+// openSheet positions the rows of the sheet after the header, the caller closes f on error
+func (r *XLSXReader) openSheet(f *excelize.File) (*rowFile, error) {
+	sheet := r.Sheet
+	if sheet == "" {
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("xlsx file %s has no sheet", r.FilePath)
+		}
+		sheet = sheets[0]
+	}
+
+	props, err := f.GetWorkbookProps()
+	if err != nil {
+		return nil, fmt.Errorf("reading workbook properties: %w", err)
+	}
+
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("reading sheet %s: %w", sheet, err)
+	}
+
+	sr := &sheetRows{
+		file:     f,
+		rows:     rows,
+		date1904: props.Date1904 != nil && *props.Date1904,
+		layout:   r.DateLayout,
+	}
+
+	headerRow := max(r.HeaderRow, 1)
+	for sr.line < headerRow {
+		if !rows.Next() {
+			rows.Close()
+			if err := rows.Error(); err != nil {
+				return nil, fmt.Errorf("reading sheet %s: %w", sheet, err)
+			}
+			return nil, fmt.Errorf("reading sheet %s: header row %d not found", sheet, headerRow)
+		}
+		sr.line++
+	}
+
+	header, err := rows.Columns(excelize.Options{RawCellValue: true})
+	if err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("reading XLSX header: %w", err)
+	}
+
+	for i, field := range header {
+		header[i] = strings.TrimSpace(field)
+		for _, column := range r.DateColumns {
+			if strings.EqualFold(header[i], column) {
+				sr.dateColumns = append(sr.dateColumns, i)
+			}
+		}
+	}
+
+	return &rowFile{Closer: sr, header: header, rows: sr}, nil
+}
+
+// sheetRows reads the rows of a worksheet like a csv.Reader, skipping the empty ones
+type sheetRows struct {
+	file        *excelize.File
+	rows        *excelize.Rows
+	line        int // Row number of the last row read
+	dateColumns []int
+	date1904    bool
+	layout      string
+}
+
+func (s *sheetRows) Read() ([]string, error) {
+	for s.rows.Next() {
+		s.line++
+
+		row, err := s.rows.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", s.line, err)
+		}
+		if isBlankRow(row) {
+			continue
+		}
+
+		for _, i := range s.dateColumns {
+			if i < len(row) {
+				row[i] = s.formatSerialDate(row[i])
+			}
+		}
+
+		return row, nil
+	}
+
+	if err := s.rows.Error(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// FieldPos returns the row number of the last row read, the column isn't tracked
+func (s *sheetRows) FieldPos(int) (line, column int) {
+	return s.line, 0
+}
+
+func (s *sheetRows) Close() error {
+	s.rows.Close()
+	return s.file.Close()
+}
+
+// formatSerialDate formats a cell holding an Excel serial date, other cells are left as they are
+func (s *sheetRows) formatSerialDate(cell string) string {
+	serial, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+	if err != nil || s.layout == "" {
+		return cell
+	}
+
+	t, err := excelize.ExcelDateToTime(serial, s.date1904)
+	if err != nil {
+		return cell
+	}
+	return t.Format(s.layout)
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package fileutil_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tirasundara/reconciliation-service/pkg/fileutil"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXLSXReader_RawValuesAndSerialDates(t *testing.T) {
+	fp := writeTestXLSX(t, "Statement", [][]any{
+		{"ACME Bank statement"}, // Title above the header
+		{},
+		{" id ", "amount", "date"},
+		{"A", 1234567.89, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{},
+		{"B", 0.1, "2025-01-16"}, // Date typed as text
+		{"C", "oops", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+	})
+
+	var rejects []fileutil.Reject
+	loader := &fileutil.Loader[[]string]{
+		Decoder: func(header []string) (fileutil.RowDecoder[[]string], error) {
+			if !slices.Equal(header, []string{"id", "amount", "date"}) {
+				t.Errorf("Expected the header of row 3, got %q", header)
+			}
+
+			return func(r []string) ([]string, error) {
+				if r[0] == "C" {
+					return nil, errors.New("rejected")
+				}
+				return r, nil
+			}, nil
+		},
+		Reject: func(reject fileutil.Reject) { rejects = append(rejects, reject) },
+	}
+
+	src := &fileutil.XLSXReader{
+		FilePath:    fp,
+		Sheet:       "Statement",
+		HeaderRow:   3,
+		DateColumns: []string{"date"},
+		DateLayout:  "2006-01-02",
+	}
+
+	rows, err := loader.LoadConcurrently(context.Background(), src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := [][]string{
+		{"A", "1234567.89", "2025-01-15"},
+		{"B", "0.1", "2025-01-16"},
+	}
+
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i := range expected {
+		if !slices.Equal(rows[i], expected[i]) {
+			t.Errorf("Expected row %q, got %q", expected[i], rows[i])
+		}
+	}
+
+	if len(rejects) != 1 || rejects[0].Line != 7 || rejects[0].File != fp+":Statement" {
+		t.Errorf("Expected a reject on %s:Statement row 7, got %+v", fp, rejects)
+	}
+}
+
+func TestXLSXReader_SerialDateTime(t *testing.T) {
+	fp := writeTestXLSX(t, "Sheet1", [][]any{
+		{"id", "transactionTime"},
+		{"A", time.Date(2025, 1, 15, 8, 30, 0, 0, time.UTC)},
+	})
+
+	loader := &fileutil.Loader[string]{
+		Decoder: func([]string) (fileutil.RowDecoder[string], error) {
+			return func(r []string) (string, error) { return r[1], nil }, nil
+		},
+	}
+
+	src := fileutil.NewXLSXReader(fp)
+	src.DateColumns = []string{"transactionTime"}
+	src.DateLayout = "2006-01-02T15:04:05"
+
+	times, err := loader.Load(context.Background(), src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !slices.Equal(times, []string{"2025-01-15T08:30:00"}) {
+		t.Errorf("Expected [2025-01-15T08:30:00], got %q", times)
+	}
+}
+
+func TestXLSXReader_MissingHeaderRow(t *testing.T) {
+	fp := writeTestXLSX(t, "Sheet1", [][]any{{"id", "value"}})
+
+	src := fileutil.NewXLSXReader(fp)
+	src.HeaderRow = 5
+
+	if _, err := newTestLoader().Load(context.Background(), src); err == nil {
+		t.Errorf("Expected an error for a header row past the end of the sheet")
+	}
+}
+
+// writeTestXLSX writes a workbook with a single sheet holding rows, starting at A1
+func writeTestXLSX(t *testing.T, sheet string, rows [][]any) string {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		t.Fatalf("Failed to name sheet: %v", err)
+	}
+
+	for i, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			t.Fatalf("Failed to write row: %v", err)
+		}
+	}
+
+	fp := filepath.Join(t.TempDir(), "workbook.xlsx")
+	if err := f.SaveAs(fp); err != nil {
+		t.Fatalf("Failed to save workbook: %v", err)
+	}
+
+	return fp
+}